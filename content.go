@@ -0,0 +1,475 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// pageAttrs holds the subset of a /Pages tree node's dictionary that
+// inherits down to its children per the PDF spec (7.7.3.4): a node that
+// doesn't set one of these itself uses its parent's value.
+type pageAttrs struct {
+	resources Value
+	mediaBox  [4]float64
+	rotate    int64
+}
+
+// buildFpdf walks Trailer -> /Root -> /Pages, replaying each leaf page's
+// content stream onto a new *Fpdf so the loaded document can be edited
+// and re-emitted through the rest of the package's normal API.
+func (r *pdfReader) buildFpdf() (f *Fpdf, err error) {
+	root := r.xrefTable.trailer.Key("Root")
+	if root.Kind() != Dict {
+		return nil, errors.New("pdf: trailer has no /Root")
+	}
+	pages := root.Key("Pages")
+	if pages.Kind() != Dict {
+		return nil, errors.New("pdf: document catalog has no /Pages")
+	}
+
+	f = New("P", "pt", "A4", "")
+	inherited := pageAttrs{mediaBox: [4]float64{0, 0, 612, 792}}
+	if err := r.walkPages(f, pages, inherited); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// walkPages recurses through a /Pages node's /Kids, applying attribute
+// inheritance, and renders each leaf (a node with no /Kids) as a page.
+func (r *pdfReader) walkPages(f *Fpdf, node Value, inherited pageAttrs) error {
+	if node.Kind() != Dict {
+		return nil
+	}
+
+	attrs := inherited
+	if res := node.Key("Resources"); res.Kind() == Dict {
+		attrs.resources = res
+	}
+	if mb := node.Key("MediaBox"); mb.Kind() == Array && mb.Len() == 4 {
+		attrs.mediaBox = rectFromArray(mb)
+	}
+	if rotate := node.Key("Rotate"); rotate.Kind() == Integer {
+		attrs.rotate = rotate.Int64()
+	}
+
+	if kids := node.Key("Kids"); kids.Kind() == Array {
+		for i := 0; i < kids.Len(); i++ {
+			if err := r.walkPages(f, kids.Index(i), attrs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return r.renderPage(f, node, attrs)
+}
+
+func rectFromArray(v Value) [4]float64 {
+	var rect [4]float64
+	for i := 0; i < 4; i++ {
+		rect[i] = v.Index(i).Float64()
+	}
+	return rect
+}
+
+// renderPage adds page to f at its inherited size and replays its
+// content stream(s) over the page.
+func (r *pdfReader) renderPage(f *Fpdf, page Value, attrs pageAttrs) error {
+	width := attrs.mediaBox[2] - attrs.mediaBox[0]
+	height := attrs.mediaBox[3] - attrs.mediaBox[1]
+	orientation := "P"
+	if width > height {
+		orientation = "L"
+	}
+	f.AddPageFormat(orientation, SizeType{Wd: width, Ht: height})
+
+	content, err := readPageContent(page)
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+
+	cs := &contentState{f: f, pageHeight: height, resources: attrs.resources}
+	return r.runContentStream(cs, content)
+}
+
+// readPageContent returns the concatenation of a page's /Contents
+// stream(s), which may be a single Stream or an Array of them.
+func readPageContent(page Value) ([]byte, error) {
+	contents := page.Key("Contents")
+	switch contents.Kind() {
+	case Stream:
+		return io.ReadAll(contents.Reader())
+	case Array:
+		var buf bytes.Buffer
+		for i := 0; i < contents.Len(); i++ {
+			b, err := io.ReadAll(contents.Index(i).Reader())
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(b)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, nil
+}
+
+// contentState is the graphics/text state this minimal content-stream
+// interpreter tracks between operators. There's no general transformation
+// matrix, clipping, or dash pattern support - just enough to round-trip
+// simple, unrotated pages onto gofpdf's existing drawing and text calls.
+type contentState struct {
+	f          *Fpdf
+	pageHeight float64
+	resources  Value
+
+	// current path, in PDF user space, built up by m/l/c/re/h and
+	// consumed by a painting operator (S/f/F/B/n).
+	path    []pointType
+	isRect  bool
+	rect    [4]float64 // x, y, w, h
+	stack   []contentState
+	tx, ty  float64 // text line matrix translation
+	fontKey string
+}
+
+type pointType struct{ x, y float64 }
+
+// toPage converts a PDF user-space point (origin bottom-left) to the
+// top-left-origin coordinate space gofpdf's drawing calls expect.
+func (cs *contentState) toPage(x, y float64) (float64, float64) {
+	return x, cs.pageHeight - y
+}
+
+var contentOperators = map[string]bool{
+	"q": true, "Q": true, "cm": true,
+	"BT": true, "ET": true,
+	"Tj": true, "TJ": true, "'": true, "\"": true,
+	"Tf": true, "Td": true, "TD": true, "Tm": true, "T*": true,
+	"re": true, "m": true, "l": true, "c": true, "h": true,
+	"S": true, "s": true, "f": true, "F": true, "f*": true, "B": true, "B*": true, "n": true,
+	"rg": true, "RG": true, "g": true, "G": true, "w": true,
+	"Do": true, "W": true, "W*": true,
+}
+
+// runContentStream tokenizes content (a decoded page or form-XObject
+// content stream) with the same lexer used for the rest of the document
+// and dispatches each operator to execOp.
+func (r *pdfReader) runContentStream(cs *contentState, content []byte) error {
+	saved := r.lexer
+	r.lexer = newPDFLexerBytes(content)
+	defer func() { r.lexer = saved }()
+
+	var operands []Value
+	for {
+		tok, err := r.lexer.Next()
+		if err != nil {
+			return err
+		}
+		if tok.kind == tokEOF {
+			return nil
+		}
+		if op, ok := tok.val.(string); ok && tok.kind == tokKeyword && op != "true" && op != "false" && op != "null" {
+			if contentOperators[op] {
+				if err := r.execOp(cs, op, operands); err != nil {
+					return err
+				}
+			}
+			operands = operands[:0]
+			continue
+		}
+		v, err := r.parsePDFObjectFrom(tok)
+		if err != nil {
+			return err
+		}
+		operands = append(operands, v)
+	}
+}
+
+func (r *pdfReader) execOp(cs *contentState, op string, args []Value) error {
+	f := cs.f
+	switch op {
+	case "q":
+		cs.stack = append(cs.stack, *cs)
+	case "Q":
+		if n := len(cs.stack); n > 0 {
+			saved := cs.stack[n-1]
+			cs.stack = cs.stack[:n-1]
+			*cs = saved
+		}
+	case "cm":
+		// A full transformation-matrix stack isn't modeled; content
+		// using cm for anything beyond the page's own coordinate
+		// system will render in the wrong place.
+	case "BT":
+		cs.tx, cs.ty = 0, 0
+	case "ET":
+	case "Td", "TD":
+		if len(args) < 2 {
+			return nil
+		}
+		cs.tx += args[0].Float64()
+		cs.ty += args[1].Float64()
+	case "Tm":
+		if len(args) < 6 {
+			return nil
+		}
+		cs.tx = args[4].Float64()
+		cs.ty = args[5].Float64()
+	case "T*":
+		// Leading isn't tracked, so this is treated as a same-line no-op.
+	case "Tf":
+		if len(args) < 2 {
+			return nil
+		}
+		cs.fontKey = args[0].Name()
+		size := args[1].Float64()
+		family, style := r.resolveFont(cs.resources, cs.fontKey, f)
+		f.SetFont(family, style, size)
+	case "Tj":
+		if len(args) < 1 {
+			return nil
+		}
+		x, y := cs.toPage(cs.tx, cs.ty)
+		f.SetXY(x, y)
+		f.Text(x, y, args[0].RawString())
+	case "'", "\"":
+		if len(args) < 1 {
+			return nil
+		}
+		x, y := cs.toPage(cs.tx, cs.ty)
+		f.SetXY(x, y)
+		f.Text(x, y, args[len(args)-1].RawString())
+	case "TJ":
+		if len(args) < 1 || args[0].Kind() != Array {
+			return nil
+		}
+		var sb strings.Builder
+		arr := args[0]
+		for i := 0; i < arr.Len(); i++ {
+			if elem := arr.Index(i); elem.Kind() == String {
+				sb.WriteString(elem.RawString())
+			}
+		}
+		x, y := cs.toPage(cs.tx, cs.ty)
+		f.SetXY(x, y)
+		f.Text(x, y, sb.String())
+	case "re":
+		if len(args) < 4 {
+			return nil
+		}
+		cs.isRect = true
+		cs.rect = [4]float64{args[0].Float64(), args[1].Float64(), args[2].Float64(), args[3].Float64()}
+		cs.path = nil
+	case "m":
+		if len(args) < 2 {
+			return nil
+		}
+		cs.isRect = false
+		cs.path = []pointType{{args[0].Float64(), args[1].Float64()}}
+	case "l":
+		if len(args) < 2 {
+			return nil
+		}
+		cs.path = append(cs.path, pointType{args[0].Float64(), args[1].Float64()})
+	case "c":
+		// Bezier curves are approximated by a straight line to their
+		// endpoint; curved content will render as chords.
+		if len(args) < 6 {
+			return nil
+		}
+		cs.path = append(cs.path, pointType{args[4].Float64(), args[5].Float64()})
+	case "h":
+		if len(cs.path) > 0 {
+			cs.path = append(cs.path, cs.path[0])
+		}
+	case "S", "s":
+		cs.paint(false, true)
+	case "f", "F", "f*":
+		cs.paint(true, false)
+	case "B", "B*":
+		cs.paint(true, true)
+	case "n":
+		cs.path = nil
+		cs.isRect = false
+	case "rg":
+		if len(args) < 3 {
+			return nil
+		}
+		f.SetFillColor(colorByte(args[0]), colorByte(args[1]), colorByte(args[2]))
+	case "RG":
+		if len(args) < 3 {
+			return nil
+		}
+		f.SetDrawColor(colorByte(args[0]), colorByte(args[1]), colorByte(args[2]))
+	case "g":
+		if len(args) < 1 {
+			return nil
+		}
+		gray := colorByte(args[0])
+		f.SetFillColor(gray, gray, gray)
+	case "G":
+		if len(args) < 1 {
+			return nil
+		}
+		gray := colorByte(args[0])
+		f.SetDrawColor(gray, gray, gray)
+	case "w":
+		if len(args) < 1 {
+			return nil
+		}
+		f.SetLineWidth(args[0].Float64())
+	case "Do":
+		if len(args) < 1 {
+			return nil
+		}
+		return r.execDo(cs, args[0].Name())
+	case "W", "W*":
+		// Clipping paths are not applied; the path is left in place for
+		// the paint operator that follows, matching the spec's ordering.
+	}
+	return nil
+}
+
+func colorByte(v Value) int {
+	return int(v.Float64()*255 + 0.5)
+}
+
+// paint renders the path or rectangle accumulated since the last m/re,
+// then clears it per the spec (a path is consumed by the operator that
+// paints it). A rectangle is filled and/or stroked as asked; any other
+// path is drawn as its line segments only, so it is not filled even
+// when the operator asks for a fill.
+func (cs *contentState) paint(fill, stroke bool) {
+	style := ""
+	switch {
+	case fill && stroke:
+		style = "FD"
+	case fill:
+		style = "F"
+	case stroke:
+		style = "D"
+	}
+
+	if cs.isRect {
+		x, y := cs.toPage(cs.rect[0], cs.rect[1]+cs.rect[3])
+		if style != "" {
+			cs.f.Rect(x, y, cs.rect[2], cs.rect[3], style)
+		}
+	} else if len(cs.path) > 1 && style != "" {
+		for i := 1; i < len(cs.path); i++ {
+			x1, y1 := cs.toPage(cs.path[i-1].x, cs.path[i-1].y)
+			x2, y2 := cs.toPage(cs.path[i].x, cs.path[i].y)
+			cs.f.Line(x1, y1, x2, y2)
+		}
+	}
+
+	cs.path = nil
+	cs.isRect = false
+}
+
+// execDo replays a /XObject named name from resources: a /Form XObject
+// is a nested content stream and is executed recursively (falling back
+// to the parent's /Resources if it has none of its own); a /Image
+// XObject is not reconstructed onto the page since gofpdf's image API
+// needs a real image file rather than raw sample data.
+func (r *pdfReader) execDo(cs *contentState, name string) error {
+	xobj := cs.resources.Key("XObject").Key(name)
+	if xobj.Kind() != Stream {
+		return nil
+	}
+	if xobj.Key("Subtype").Name() != "Form" {
+		return nil
+	}
+
+	resources := xobj.Key("Resources")
+	if resources.Kind() != Dict {
+		resources = cs.resources
+	}
+	body, err := io.ReadAll(xobj.Reader())
+	if err != nil {
+		return err
+	}
+
+	nested := &contentState{f: cs.f, pageHeight: cs.pageHeight, resources: resources, tx: cs.tx, ty: cs.ty}
+	return r.runContentStream(nested, body)
+}
+
+// resolveFont maps the resource dictionary entry for fontKey to a
+// core-14 family/style pair gofpdf already knows, or (when the font is
+// embedded) registers it as a UTF-8 font under its PostScript name.
+func (r *pdfReader) resolveFont(resources Value, fontKey string, f *Fpdf) (family, style string) {
+	fontDict := resources.Key("Font").Key(fontKey)
+	baseFont := fontDict.Key("BaseFont").Name()
+
+	family, style = coreFontFamily(baseFont)
+
+	descriptor := fontDict.Key("FontDescriptor")
+	for _, key := range []string{"FontFile2", "FontFile3", "FontFile"} {
+		fontFile := descriptor.Key(key)
+		if fontFile.Kind() != Stream {
+			continue
+		}
+		data, err := io.ReadAll(fontFile.Reader())
+		if err != nil || len(data) == 0 {
+			break
+		}
+		embeddedFamily := baseFont
+		if embeddedFamily == "" {
+			embeddedFamily = fontKey
+		}
+		f.AddUTF8FontFromBytes(embeddedFamily, style, data)
+		return embeddedFamily, style
+	}
+
+	return family, style
+}
+
+// coreFontFamily guesses one of gofpdf's core-14 families and a style
+// string ("", "B", "I", "BI") from a PDF /BaseFont name such as
+// "ABCDEF+Helvetica-BoldOblique".
+func coreFontFamily(baseFont string) (family, style string) {
+	if i := strings.IndexByte(baseFont, '+'); i == 6 {
+		baseFont = baseFont[i+1:]
+	}
+
+	lower := strings.ToLower(baseFont)
+	switch {
+	case strings.Contains(lower, "courier"):
+		family = "Courier"
+	case strings.Contains(lower, "times"):
+		family = "Times"
+	default:
+		family = "Arial"
+	}
+
+	if strings.Contains(lower, "bold") {
+		style += "B"
+	}
+	if strings.Contains(lower, "italic") || strings.Contains(lower, "oblique") {
+		style += "I"
+	}
+	return family, style
+}
@@ -0,0 +1,125 @@
+package gofpdf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExecOpQRestoresWithoutGrowingStack guards the q/Q nesting bug: a
+// Q must pop exactly one saved state, not re-append whatever was nested
+// under it, or the stack grows without bound across further Qs.
+func TestExecOpQRestoresWithoutGrowingStack(t *testing.T) {
+	var r pdfReader
+	cs := &contentState{pageHeight: 100}
+
+	for i := 0; i < 3; i++ {
+		if err := r.execOp(cs, "q", nil); err != nil {
+			t.Fatalf("q: %v", err)
+		}
+	}
+	if len(cs.stack) != 3 {
+		t.Fatalf("after 3 q: len(stack) = %d, want 3", len(cs.stack))
+	}
+
+	if err := r.execOp(cs, "Q", nil); err != nil {
+		t.Fatalf("Q: %v", err)
+	}
+	if len(cs.stack) != 2 {
+		t.Fatalf("after 1 Q: len(stack) = %d, want 2", len(cs.stack))
+	}
+
+	if err := r.execOp(cs, "Q", nil); err != nil {
+		t.Fatalf("Q: %v", err)
+	}
+	if err := r.execOp(cs, "Q", nil); err != nil {
+		t.Fatalf("Q: %v", err)
+	}
+	if len(cs.stack) != 0 {
+		t.Fatalf("after popping every q: len(stack) = %d, want 0", len(cs.stack))
+	}
+}
+
+// TestExecOpTextAndPathState checks the operators that only touch
+// contentState bookkeeping (no Fpdf call): BT/Td accumulate the text
+// line position, and re/m/l/h build up the path state paint() consumes.
+func TestExecOpTextAndPathState(t *testing.T) {
+	var r pdfReader
+	cs := &contentState{pageHeight: 100}
+
+	if err := r.execOp(cs, "BT", nil); err != nil {
+		t.Fatalf("BT: %v", err)
+	}
+	if err := r.execOp(cs, "Td", []Value{newValue(nil, Real, 10.0), newValue(nil, Real, 5.0)}); err != nil {
+		t.Fatalf("Td: %v", err)
+	}
+	if cs.tx != 10 || cs.ty != 5 {
+		t.Fatalf("tx,ty = %v,%v, want 10,5", cs.tx, cs.ty)
+	}
+	if x, y := cs.toPage(cs.tx, cs.ty); x != 10 || y != 95 {
+		t.Errorf("toPage(10,5) = %v,%v, want 10,95", x, y)
+	}
+
+	if err := r.execOp(cs, "re", []Value{
+		newValue(nil, Real, 1.0), newValue(nil, Real, 2.0),
+		newValue(nil, Real, 3.0), newValue(nil, Real, 4.0),
+	}); err != nil {
+		t.Fatalf("re: %v", err)
+	}
+	if !cs.isRect || cs.rect != [4]float64{1, 2, 3, 4} {
+		t.Fatalf("rect = %v, isRect = %v", cs.rect, cs.isRect)
+	}
+
+	if err := r.execOp(cs, "m", []Value{newValue(nil, Real, 0.0), newValue(nil, Real, 0.0)}); err != nil {
+		t.Fatalf("m: %v", err)
+	}
+	if cs.isRect {
+		t.Errorf("m should clear isRect")
+	}
+	if err := r.execOp(cs, "l", []Value{newValue(nil, Real, 1.0), newValue(nil, Real, 1.0)}); err != nil {
+		t.Fatalf("l: %v", err)
+	}
+	if err := r.execOp(cs, "h", nil); err != nil {
+		t.Fatalf("h: %v", err)
+	}
+	if len(cs.path) != 3 || cs.path[2] != cs.path[0] {
+		t.Errorf("h should close the path back to its start, path = %v", cs.path)
+	}
+}
+
+// TestReadPageContentConcatenatesArray checks that a page whose
+// /Contents is an array of streams gets them concatenated, each followed
+// by a newline, matching how the PDF spec treats split content streams
+// as one logical stream.
+func TestReadPageContentConcatenatesArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streams.bin")
+	if err := os.WriteFile(path, []byte("firstsecond"), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening fixture: %v", err)
+	}
+	defer f.Close()
+
+	stream := func(offset, length int64) Value {
+		return newValue(nil, Stream, &streamValue{
+			dict:   map[string]Value{},
+			file:   f,
+			offset: offset,
+			length: length,
+		})
+	}
+
+	page := newValue(nil, Dict, map[string]Value{
+		"Contents": newValue(nil, Array, []Value{stream(0, 5), stream(5, 6)}),
+	})
+
+	got, err := readPageContent(page)
+	if err != nil {
+		t.Fatalf("readPageContent: %v", err)
+	}
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("readPageContent = %q, want %q", got, "first\nsecond\n")
+	}
+}
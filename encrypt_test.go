@@ -0,0 +1,205 @@
+package gofpdf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"testing"
+)
+
+// aesCBCEncryptForTest builds the IV-prefixed, PKCS#7-padded ciphertext
+// aesCBCDecrypt expects, so its round trip can be tested without a real
+// encrypted PDF fixture.
+func aesCBCEncryptForTest(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+
+	padLen := aes.BlockSize - len(plain)%aes.BlockSize
+	padded := append(append([]byte{}, plain...), make([]byte, padLen)...)
+	for i := len(plain); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	ct := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, padded)
+
+	return append(append([]byte{}, iv...), ct...)
+}
+
+// TestPadPassword checks algorithm 3.2's fixed padding: short passwords
+// are padded out to 32 bytes with the standard pad string, and a
+// password of exactly 32 bytes passes through unchanged.
+func TestPadPassword(t *testing.T) {
+	got := padPassword([]byte("abc"))
+	if len(got) != 32 {
+		t.Fatalf("len = %d, want 32", len(got))
+	}
+	if string(got[:3]) != "abc" {
+		t.Errorf("prefix = %q, want %q", got[:3], "abc")
+	}
+	if string(got[3:]) != string(standardPad[:29]) {
+		t.Errorf("pad suffix doesn't match standardPad")
+	}
+
+	got = padPassword(standardPad)
+	if len(got) != 32 || string(got) != string(standardPad) {
+		t.Errorf("a password already == standardPad should pass through unchanged")
+	}
+}
+
+// TestObjectKey checks algorithm 3.1's per-object key derivation: the
+// output is truncated to min(file key length + 5, 16) bytes, and the
+// AESV2 "sAlT" constant changes the key relative to RC4.
+func TestObjectKey(t *testing.T) {
+	fileKey := []byte{1, 2, 3, 4, 5}
+
+	rc4Key := objectKey(fileKey, 7, 0, "V2")
+	if len(rc4Key) != 10 {
+		t.Fatalf("len(rc4Key) = %d, want 10 (5 + 5)", len(rc4Key))
+	}
+
+	aesKey := objectKey(fileKey, 7, 0, "AESV2")
+	if len(aesKey) != 10 {
+		t.Fatalf("len(aesKey) = %d, want 10", len(aesKey))
+	}
+	if string(rc4Key) == string(aesKey) {
+		t.Errorf("AESV2's sAlT suffix should change the derived key relative to RC4")
+	}
+
+	longKey := make([]byte, 16)
+	if got := len(objectKey(longKey, 1, 0, "V2")); got != 16 {
+		t.Errorf("len = %d, want 16 (clamped)", got)
+	}
+}
+
+// TestRC4Crypt checks rc4Crypt against the well-known "Key"/"Plaintext"
+// test vector.
+func TestRC4Crypt(t *testing.T) {
+	got := rc4Crypt([]byte("Key"), []byte("Plaintext"))
+	want := []byte{0xBB, 0xF3, 0x16, 0xE8, 0xD9, 0x40, 0xAF, 0x0A, 0xD3}
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rc4Crypt(%q, %q) = % X, want % X", "Key", "Plaintext", got, want)
+		}
+	}
+
+	// RC4 is symmetric: decrypting the ciphertext with the same key
+	// recovers the plaintext.
+	if string(rc4Crypt([]byte("Key"), got)) != "Plaintext" {
+		t.Errorf("rc4Crypt isn't its own inverse")
+	}
+}
+
+// TestAESCBCDecryptRoundTrip encrypts a payload with the standard
+// IV-prefixed, PKCS#7-padded scheme aesCBCDecrypt expects, then checks
+// it recovers the original plaintext.
+func TestAESCBCDecryptRoundTrip(t *testing.T) {
+	key := make([]byte, 16)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	plain := []byte("a secret object's bytes")
+
+	ct := aesCBCEncryptForTest(t, key, plain)
+	got := aesCBCDecrypt(key, ct)
+	if string(got) != string(plain) {
+		t.Errorf("aesCBCDecrypt round trip = %q, want %q", got, plain)
+	}
+}
+
+func TestAESCBCDecryptShortInputReturnsNil(t *testing.T) {
+	key := make([]byte, 16)
+	if got := aesCBCDecrypt(key, make([]byte, 16)); got != nil {
+		t.Errorf("data shorter than one IV + one block should return nil, got %v", got)
+	}
+}
+
+// buildR2to4EncryptFixture builds an /Encrypt dict and trailer /ID for
+// revision rev whose /U entry is consistent with userPassword, by
+// replicating algorithm 3.2 (key derivation) and 3.4/3.5 (/U) the way a
+// real PDF writer would have produced them.
+func buildR2to4EncryptFixture(t *testing.T, userPassword string, rev, length int) (encDict, trailer Value) {
+	t.Helper()
+
+	o := standardPad // stand in for a real /O; only its length matters here
+	p := int32(-4)
+	id := []byte("0123456789abcdef")
+
+	h := md5.New()
+	h.Write(padPassword([]byte(userPassword)))
+	h.Write(o[:32])
+	h.Write([]byte{byte(p), byte(p >> 8), byte(p >> 16), byte(p >> 24)})
+	h.Write(id)
+	key := h.Sum(nil)
+
+	n := length / 8
+	if rev >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:n])
+			key = sum[:]
+		}
+	}
+	key = key[:n]
+
+	u := expectedUserHash(key, id, rev)
+	uEntry := make([]byte, 32)
+	copy(uEntry, u)
+
+	encDict = newValue(nil, Dict, map[string]Value{
+		"O":      newValue(nil, String, string(o)),
+		"U":      newValue(nil, String, string(uEntry)),
+		"P":      newValue(nil, Integer, int64(p)),
+		"R":      newValue(nil, Integer, int64(rev)),
+		"Length": newValue(nil, Integer, int64(length)),
+	})
+	trailer = newValue(nil, Dict, map[string]Value{
+		"ID": newValue(nil, Array, []Value{newValue(nil, String, string(id))}),
+	})
+	return encDict, trailer
+}
+
+// TestComputeFileKeyR2to4AcceptsCorrectPassword checks that the right
+// password derives a key and is accepted against /U per algorithm 3.6.
+func TestComputeFileKeyR2to4AcceptsCorrectPassword(t *testing.T) {
+	encDict, trailer := buildR2to4EncryptFixture(t, "hunter2", 3, 128)
+
+	key, err := computeFileKeyR2to4([]byte("hunter2"), encDict, trailer, 3, 128, true)
+	if err != nil {
+		t.Fatalf("computeFileKeyR2to4: %v", err)
+	}
+	if len(key) != 16 {
+		t.Errorf("len(key) = %d, want 16", len(key))
+	}
+}
+
+// TestComputeFileKeyR2to4RejectsWrongPassword checks that a wrong
+// password is rejected rather than silently producing a garbage key.
+func TestComputeFileKeyR2to4RejectsWrongPassword(t *testing.T) {
+	encDict, trailer := buildR2to4EncryptFixture(t, "hunter2", 3, 128)
+
+	if _, err := computeFileKeyR2to4([]byte("wrong"), encDict, trailer, 3, 128, true); err == nil {
+		t.Fatalf("computeFileKeyR2to4 with a wrong password = nil error, want an error")
+	}
+}
+
+// TestComputeFileKeyR2to4RevisionTwo exercises the shorter algorithm
+// 3.4 comparison path used by revision 2 (no 50-round MD5 hardening,
+// /U is the full 32-byte RC4 output rather than a 16-byte digest).
+func TestComputeFileKeyR2to4RevisionTwo(t *testing.T) {
+	encDict, trailer := buildR2to4EncryptFixture(t, "", 2, 40)
+
+	if _, err := computeFileKeyR2to4([]byte(""), encDict, trailer, 2, 40, true); err != nil {
+		t.Fatalf("computeFileKeyR2to4: %v", err)
+	}
+	if _, err := computeFileKeyR2to4([]byte("nope"), encDict, trailer, 2, 40, true); err == nil {
+		t.Fatalf("computeFileKeyR2to4 with a wrong password = nil error, want an error")
+	}
+}
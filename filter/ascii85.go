@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"io"
+)
+
+// ascii85Filter implements the ASCII85Decode PDF filter. PDF's variant
+// terminates the encoded data with "~>" rather than relying on length
+// alone, which Go's encoding/ascii85 tolerates as trailing garbage once
+// stripped.
+type ascii85Filter struct{}
+
+func (ascii85Filter) Decode(r io.Reader, parms Params) (io.Reader, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.Index(raw, []byte("~>")); i >= 0 {
+		raw = raw[:i]
+	}
+
+	decoded := make([]byte, len(raw))
+	n, _, err := ascii85.Decode(decoded, raw, true)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(decoded[:n]), nil
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+import "io"
+
+// ccittFaxFilter is a placeholder for the CCITTFaxDecode PDF filter
+// (Group 3/4 fax compression, used almost exclusively for scanned
+// black-and-white page images). Decoding it needs a real G3/G4 bitmap
+// decoder, which this package does not yet vendor; registering the name
+// here means callers get ErrUnsupported instead of a missing-filter
+// error, and can Register their own implementation if they have one.
+type ccittFaxFilter struct{}
+
+func (ccittFaxFilter) Decode(r io.Reader, parms Params) (io.Reader, error) {
+	return nil, ErrUnsupported
+}
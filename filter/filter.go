@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package filter decodes PDF stream bodies. Each PDF filter name
+// (FlateDecode, ASCII85Decode, ...) is implemented as a Filter and kept
+// in a package-level registry, so callers outside this package can teach
+// it about additional or private filter names via Register.
+package filter
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnsupported is returned by a registered Filter's Decode when it
+// cannot decode its input; the built-in CCITTFaxDecode filter always
+// returns it. Get reports an unknown filter name with its bool result.
+var ErrUnsupported = errors.New("filter: unsupported filter")
+
+// Params carries the entries of a stream's /DecodeParms dictionary (or
+// of the element of a /DecodeParms array lined up with a given filter)
+// through to a Filter. Keys match the PDF dictionary key names exactly,
+// e.g. "Predictor", "Columns", "Colors", "BitsPerComponent",
+// "EarlyChange".
+type Params map[string]interface{}
+
+// Int returns the integer value of key, or def if key is absent or not
+// a number. A float64 value is truncated to an int.
+func (p Params) Int(key string, def int) int {
+	switch v := p[key].(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+// Name returns the name value of key, or "" if key is absent.
+func (p Params) Name(key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+
+// Filter decodes a single stage of a PDF stream's filter chain. r yields
+// the filtered bytes; the returned Reader yields the decoded bytes.
+type Filter interface {
+	Decode(r io.Reader, parms Params) (io.Reader, error)
+}
+
+var registry = map[string]Filter{}
+
+// Register adds f to the registry under name, replacing any filter
+// already registered under that name. It is called from this package's
+// init for the built-in filter names, and is exported so callers can
+// register decoders for private or vendor-specific filter names.
+func Register(name string, f Filter) {
+	registry[name] = f
+}
+
+// Get returns the Filter registered under name, if any.
+func Get(name string) (Filter, bool) {
+	f, ok := registry[name]
+	return f, ok
+}
+
+func init() {
+	Register("FlateDecode", flateFilter{})
+	Register("ASCII85Decode", ascii85Filter{})
+	Register("ASCIIHexDecode", asciiHexFilter{})
+	Register("LZWDecode", lzwFilter{})
+	Register("RunLengthDecode", runLengthFilter{})
+	Register("CCITTFaxDecode", ccittFaxFilter{})
+}
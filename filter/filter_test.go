@@ -0,0 +1,288 @@
+package filter
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"encoding/ascii85"
+	"io"
+	"testing"
+)
+
+func decodeAll(t *testing.T, f Filter, raw []byte, parms Params) []byte {
+	t.Helper()
+	r, err := f.Decode(bytes.NewReader(raw), parms)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading decoded output: %v", err)
+	}
+	return out
+}
+
+// TestFlateDecodeRoundTrip checks plain zlib-wrapped deflate with no
+// predictor.
+func TestFlateDecodeRoundTrip(t *testing.T) {
+	want := []byte("the quick brown fox jumps over the lazy dog")
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(want)
+	zw.Close()
+
+	got := decodeAll(t, flateFilter{}, buf.Bytes(), nil)
+	if string(got) != string(want) {
+		t.Errorf("FlateDecode = %q, want %q", got, want)
+	}
+}
+
+// TestFlateDecodeWithPNGSubPredictor checks that a PNG Sub-filtered
+// (predictor 15, 1 byte/pixel) row is reversed after inflating.
+func TestFlateDecodeWithPNGSubPredictor(t *testing.T) {
+	row := []byte{10, 20, 30, 40}
+	filtered := make([]byte, len(row)+1)
+	filtered[0] = 1 // Sub
+	prev := byte(0)
+	for i, b := range row {
+		filtered[i+1] = b - prev
+		prev = b
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(filtered)
+	zw.Close()
+
+	parms := Params{"Predictor": int64(15), "Columns": int64(4), "Colors": int64(1), "BitsPerComponent": int64(8)}
+	got := decodeAll(t, flateFilter{}, buf.Bytes(), parms)
+	if !bytes.Equal(got, row) {
+		t.Errorf("FlateDecode with Sub predictor = %v, want %v", got, row)
+	}
+}
+
+// TestApplyPredictorPNGFilters checks each PNG per-row filter type
+// (None/Sub/Up/Average/Paeth) reconstructs a two-row image back to its
+// original bytes.
+func TestApplyPredictorPNGFilters(t *testing.T) {
+	rows := [][]byte{
+		{100, 150, 200},
+		{110, 140, 210},
+	}
+	tests := []struct {
+		name       string
+		filterType byte
+	}{
+		{"None", 0},
+		{"Sub", 1},
+		{"Up", 2},
+		{"Average", 3},
+		{"Paeth", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filtered []byte
+			prev := make([]byte, 3)
+			for _, row := range rows {
+				enc := make([]byte, 3)
+				for i, b := range row {
+					var a, c byte
+					if i >= 1 {
+						a = row[i-1]
+						c = prev[i-1]
+					}
+					up := prev[i]
+					switch tt.filterType {
+					case 0:
+						enc[i] = b
+					case 1:
+						enc[i] = b - a
+					case 2:
+						enc[i] = b - up
+					case 3:
+						enc[i] = b - byte((int(a)+int(up))/2)
+					case 4:
+						enc[i] = b - paeth(a, up, c)
+					}
+				}
+				filtered = append(filtered, tt.filterType)
+				filtered = append(filtered, enc...)
+				prev = row
+			}
+
+			parms := Params{"Predictor": int64(15), "Columns": int64(3), "Colors": int64(1), "BitsPerComponent": int64(8)}
+			got := applyPredictor(filtered, parms)
+			want := append(append([]byte{}, rows[0]...), rows[1]...)
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s predictor round trip = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+// TestApplyPredictorTIFF checks TIFF-style horizontal differencing
+// (Predictor 2) is undone per row.
+func TestApplyPredictorTIFF(t *testing.T) {
+	row := []byte{10, 20, 30, 40}
+	encoded := make([]byte, len(row))
+	encoded[0] = row[0]
+	for i := 1; i < len(row); i++ {
+		encoded[i] = row[i] - row[i-1]
+	}
+
+	parms := Params{"Predictor": int64(2), "Columns": int64(4), "Colors": int64(1), "BitsPerComponent": int64(8)}
+	got := applyPredictor(encoded, parms)
+	if !bytes.Equal(got, row) {
+		t.Errorf("TIFF predictor round trip = %v, want %v", got, row)
+	}
+}
+
+// TestApplyPredictorNoOp checks that Predictor 1 (or absent) leaves data
+// untouched.
+func TestApplyPredictorNoOp(t *testing.T) {
+	data := []byte{1, 2, 3}
+	if got := applyPredictor(data, nil); !bytes.Equal(got, data) {
+		t.Errorf("applyPredictor with no /Predictor = %v, want %v (unchanged)", got, data)
+	}
+}
+
+// TestLZWDecodeRoundTrip checks the LZWDecode filter against Go's
+// compress/lzw writer using the same MSB/8-bit-literal configuration PDF
+// uses with the default /EarlyChange 1.
+func TestLZWDecodeRoundTrip(t *testing.T) {
+	want := []byte("aaaaaaaaaabbbbbbbbbbccccccccccaaaaaaaaaa")
+
+	var buf bytes.Buffer
+	lw := lzw.NewWriter(&buf, lzw.MSB, 8)
+	lw.Write(want)
+	lw.Close()
+
+	got := decodeAll(t, lzwFilter{}, buf.Bytes(), nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("LZWDecode = %q, want %q", got, want)
+	}
+}
+
+// TestASCII85DecodeRoundTrip checks the ASCII85Decode filter, including
+// PDF's "~>" end-of-data marker that Go's own encoding doesn't emit.
+func TestASCII85DecodeRoundTrip(t *testing.T) {
+	want := []byte("Man is distinguished")
+
+	var buf bytes.Buffer
+	ew := ascii85.NewEncoder(&buf)
+	ew.Write(want)
+	ew.Close()
+	buf.WriteString("~>")
+
+	got := decodeAll(t, ascii85Filter{}, buf.Bytes(), nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("ASCII85Decode = %q, want %q", got, want)
+	}
+}
+
+// TestASCIIHexDecodeRoundTrip checks pairing, embedded whitespace, the
+// '>' terminator, and an odd trailing digit's implicit zero pad.
+func TestASCIIHexDecodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"basic", "68656c6c6f>", "hello"},
+		{"whitespace", "68 65 6c\n6c 6f>", "hello"},
+		{"odd trailing digit padded with 0", "6", "\x60"},
+		{"garbage after terminator ignored", "68656c6c6f>ZZZZ", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeAll(t, asciiHexFilter{}, []byte(tt.in), nil)
+			if string(got) != tt.want {
+				t.Errorf("ASCIIHexDecode(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunLengthDecode exercises the length-byte boundaries of algorithm
+// 7.4.5: a literal run at L=0 and L=127, the L=128 end-of-data marker
+// (including mid-stream), and repeated-byte runs at L=129 and L=255.
+func TestRunLengthDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{
+			name: "L=0 single literal byte",
+			in:   []byte{0, 'A'},
+			want: []byte("A"),
+		},
+		{
+			name: "L=127 128 literal bytes",
+			in:   append([]byte{127}, bytes.Repeat([]byte("x"), 128)...),
+			want: bytes.Repeat([]byte("x"), 128),
+		},
+		{
+			name: "L=128 end-of-data marker stops decoding",
+			in:   []byte{0, 'A', 128, 0, 'B'},
+			want: []byte("A"),
+		},
+		{
+			name: "L=129 repeats a byte 128 times",
+			in:   []byte{129, 'z'},
+			want: bytes.Repeat([]byte("z"), 128),
+		},
+		{
+			name: "L=255 repeats a byte 2 times",
+			in:   []byte{255, 'z'},
+			want: bytes.Repeat([]byte("z"), 2),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeAll(t, runLengthFilter{}, tt.in, nil)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("RunLengthDecode(% X) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParamsHelpers checks Params.Int and Params.Name's defaulting
+// behavior for absent or wrongly-typed keys.
+func TestParamsHelpers(t *testing.T) {
+	p := Params{"Columns": int64(4), "Predictor": float64(12), "Name": "Foo", "BadName": int64(5)}
+	if got := p.Int("Columns", 1); got != 4 {
+		t.Errorf("Int(Columns) = %d, want 4", got)
+	}
+	if got := p.Int("Predictor", 1); got != 12 {
+		t.Errorf("Int(Predictor) = %d, want 12 (float64 accepted)", got)
+	}
+	if got := p.Int("Missing", 7); got != 7 {
+		t.Errorf("Int(Missing) = %d, want the default 7", got)
+	}
+	if got := p.Name("Name"); got != "Foo" {
+		t.Errorf("Name(Name) = %q, want %q", got, "Foo")
+	}
+	if got := p.Name("BadName"); got != "" {
+		t.Errorf("Name(BadName) = %q, want \"\" (wrong underlying type)", got)
+	}
+	if got := p.Name("Missing"); got != "" {
+		t.Errorf("Name(Missing) = %q, want \"\"", got)
+	}
+}
+
+// TestGetAndRegister checks the built-in filters are registered under
+// their PDF names and Get reports absence for an unknown name.
+func TestGetAndRegister(t *testing.T) {
+	for _, name := range []string{"FlateDecode", "ASCII85Decode", "ASCIIHexDecode", "LZWDecode", "RunLengthDecode", "CCITTFaxDecode"} {
+		if _, ok := Get(name); !ok {
+			t.Errorf("Get(%q) not registered", name)
+		}
+	}
+	if _, ok := Get("NoSuchFilter"); ok {
+		t.Errorf("Get(\"NoSuchFilter\") = ok, want not found")
+	}
+}
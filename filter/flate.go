@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+// flateFilter implements the FlateDecode PDF filter: zlib-wrapped
+// deflate, optionally followed by a PNG or TIFF predictor named by
+// /DecodeParms.
+type flateFilter struct{}
+
+func (flateFilter) Decode(r io.Reader, parms Params) (io.Reader, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(applyPredictor(data, parms)), nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"compress/lzw"
+	"io"
+)
+
+// lzwFilter implements the LZWDecode PDF filter, optionally followed by
+// a PNG or TIFF predictor named by /DecodeParms, same as FlateDecode.
+//
+// Go's compress/lzw always uses the "early change" code-width bump that
+// PDF's default /EarlyChange 1 requires; documents that set
+// /EarlyChange 0 (rare in practice) are not supported.
+type lzwFilter struct{}
+
+func (lzwFilter) Decode(r io.Reader, parms Params) (io.Reader, error) {
+	lr := lzw.NewReader(r, lzw.MSB, 8)
+	defer lr.Close()
+
+	data, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(applyPredictor(data, parms)), nil
+}
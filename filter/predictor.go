@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+// applyPredictor reverses the predictor named by parms's /Predictor
+// entry, if any: 1 (or absent) means no predictor was applied, 2 selects
+// TIFF-style horizontal differencing, and 10-15 select the PNG per-row
+// filter methods (every value in that range behaves the same here, since
+// each PNG row carries its own filter-type tag).
+func applyPredictor(data []byte, parms Params) []byte {
+	predictor := parms.Int("Predictor", 1)
+	if predictor <= 1 {
+		return data
+	}
+	columns := parms.Int("Columns", 1)
+	colors := parms.Int("Colors", 1)
+	bpc := parms.Int("BitsPerComponent", 8)
+
+	bytesPerPixel := (colors*bpc + 7) / 8
+	rowBytes := (columns*colors*bpc + 7) / 8
+
+	if predictor == 2 {
+		return tiffPredictorDecode(data, rowBytes, bytesPerPixel)
+	}
+	return pngPredictorDecode(data, rowBytes, bytesPerPixel)
+}
+
+// pngPredictorDecode undoes the PNG filters (None/Sub/Up/Average/Paeth)
+// applied per output row, each row prefixed with a one-byte filter-type
+// tag.
+func pngPredictorDecode(data []byte, rowBytes, bpp int) []byte {
+	var out []byte
+	prev := make([]byte, rowBytes)
+	for len(data) >= rowBytes+1 {
+		filterType := data[0]
+		row := append([]byte(nil), data[1:1+rowBytes]...)
+		data = data[1+rowBytes:]
+
+		for i := range row {
+			var a, b, c byte
+			if i >= bpp {
+				a = row[i-bpp]
+				c = prev[i-bpp]
+			}
+			b = prev[i]
+			switch filterType {
+			case 1: // Sub
+				row[i] += a
+			case 2: // Up
+				row[i] += b
+			case 3: // Average
+				row[i] += byte((int(a) + int(b)) / 2)
+			case 4: // Paeth
+				row[i] += paeth(a, b, c)
+			}
+		}
+
+		out = append(out, row...)
+		prev = row
+	}
+	return out
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// tiffPredictorDecode undoes the horizontal component-differencing that
+// Predictor value 2 applies.
+func tiffPredictorDecode(data []byte, rowBytes, bpp int) []byte {
+	out := append([]byte(nil), data...)
+	for start := 0; start+rowBytes <= len(out); start += rowBytes {
+		row := out[start : start+rowBytes]
+		for i := bpp; i < len(row); i++ {
+			row[i] += row[i-bpp]
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"io"
+)
+
+// runLengthFilter implements the RunLengthDecode PDF filter (spec
+// section 7.4.5): each run is introduced by a length byte L followed by
+// either L+1 literal bytes (0 <= L <= 127) or a single byte to be
+// repeated 257-L times (129 <= L <= 255); L == 128 marks end-of-data.
+type runLengthFilter struct{}
+
+func (runLengthFilter) Decode(r io.Reader, parms Params) (io.Reader, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for i := 0; i < len(raw); {
+		length := raw[i]
+		i++
+		switch {
+		case length == 128:
+			i = len(raw)
+		case length < 128:
+			n := int(length) + 1
+			if i+n > len(raw) {
+				n = len(raw) - i
+			}
+			out.Write(raw[i : i+n])
+			i += n
+		default:
+			if i >= len(raw) {
+				break
+			}
+			out.Write(bytes.Repeat(raw[i:i+1], 257-int(length)))
+			i++
+		}
+	}
+	return bytes.NewReader(out.Bytes()), nil
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// tokenKind identifies the lexical class of a token produced by pdfLexer.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokInteger
+	tokReal
+	tokLitString
+	tokHexString
+	tokArrayStart
+	tokArrayEnd
+	tokDictStart
+	tokDictEnd
+	tokKeyword
+	tokStream
+)
+
+// token is a single lexical unit read from a pdfLexer. Val holds the
+// decoded payload: a string for tokName/tokLitString/tokHexString/
+// tokKeyword, an int64 for tokInteger, and a float64 for tokReal. The
+// delimiter and punctuation kinds carry a nil Val.
+type token struct {
+	kind tokenKind
+	val  interface{}
+}
+
+// pdfLexer tokenizes the body of a PDF file, wrapping a bufio.Reader over
+// an io.SectionReader. offset tracks the absolute file offset of the
+// next byte to be read (for a byte-slice lexer, the offset within the
+// slice) so callers (such as the stream keyword handler) can locate raw
+// byte ranges.
+type pdfLexer struct {
+	br     *bufio.Reader
+	offset int64
+	unread []token
+}
+
+// newPDFLexer returns a lexer that reads from r starting at offset
+// start. size bounds the section so repeated
+// reads past the end of file return io.EOF instead of blocking.
+func newPDFLexer(r io.ReaderAt, start, size int64) *pdfLexer {
+	sr := io.NewSectionReader(r, start, size)
+	return &pdfLexer{br: bufio.NewReader(sr), offset: start}
+}
+
+// newPDFLexerBytes returns a lexer that reads from an in-memory byte
+// slice, used for object streams whose contents have already been
+// decoded rather than read live from the file.
+func newPDFLexerBytes(data []byte) *pdfLexer {
+	return &pdfLexer{br: bufio.NewReader(bytes.NewReader(data))}
+}
+
+// seek repositions the lexer to read from offset within the underlying
+// file, discarding any buffered input.
+func (lx *pdfLexer) seek(r io.ReaderAt, fileSize, offset int64) {
+	lx.br = bufio.NewReader(io.NewSectionReader(r, offset, fileSize-offset))
+	lx.offset = offset
+	lx.unread = nil
+}
+
+// pushBack makes the next call(s) to Next return tok before reading any
+// further input. Calls stack, so the most recently pushed token is the
+// next one returned (LIFO), matching the order tokens were un-read in.
+func (lx *pdfLexer) pushBack(tok token) {
+	lx.unread = append(lx.unread, tok)
+}
+
+func (lx *pdfLexer) readByte() (byte, error) {
+	b, err := lx.br.ReadByte()
+	if err == nil {
+		lx.offset++
+	}
+	return b, err
+}
+
+func (lx *pdfLexer) unreadByte() {
+	lx.br.UnreadByte()
+	lx.offset--
+}
+
+// Next reads and returns the next token from the lexer, skipping
+// whitespace and comments (% to end of line).
+func (lx *pdfLexer) Next() (token, error) {
+	if n := len(lx.unread); n > 0 {
+		tok := lx.unread[n-1]
+		lx.unread = lx.unread[:n-1]
+		return tok, nil
+	}
+
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF {
+				return token{kind: tokEOF}, nil
+			}
+			return token{}, err
+		}
+
+		if isInByteArr(b, whiteSpaceChars) {
+			continue
+		}
+
+		switch b {
+		case '%':
+			if err := lx.skipComment(); err != nil {
+				return token{}, err
+			}
+			continue
+		case '/':
+			return lx.scanName()
+		case '(':
+			return lx.scanLitString()
+		case '<':
+			peek, err := lx.br.Peek(1)
+			if err == nil && len(peek) == 1 && peek[0] == '<' {
+				lx.readByte()
+				return token{kind: tokDictStart}, nil
+			}
+			return lx.scanHexString()
+		case '>':
+			peek, err := lx.br.Peek(1)
+			if err == nil && len(peek) == 1 && peek[0] == '>' {
+				lx.readByte()
+				return token{kind: tokDictEnd}, nil
+			}
+			return token{}, errors.New("pdf lexer: stray '>'")
+		case '[':
+			return token{kind: tokArrayStart}, nil
+		case ']':
+			return token{kind: tokArrayEnd}, nil
+		case '+', '-', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			lx.unreadByte()
+			return lx.scanNumber()
+		case ')':
+			return token{}, errors.New("pdf lexer: stray ')'")
+		default:
+			lx.unreadByte()
+			return lx.scanKeyword()
+		}
+	}
+}
+
+func (lx *pdfLexer) skipComment() error {
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if b == '\r' || b == '\n' {
+			return nil
+		}
+	}
+}
+
+func (lx *pdfLexer) scanName() (token, error) {
+	var buf bytes.Buffer
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return token{}, err
+		}
+		if isInByteArr(b, whiteSpaceChars) || isInByteArr(b, delimiterChars) {
+			lx.unreadByte()
+			break
+		}
+		if b == '#' {
+			hex := make([]byte, 2)
+			for i := range hex {
+				hb, err := lx.readByte()
+				if err != nil {
+					return token{}, err
+				}
+				hex[i] = hb
+			}
+			n, err := strconv.ParseUint(string(hex), 16, 8)
+			if err != nil {
+				return token{}, fmt.Errorf("pdf lexer: bad name escape: %w", err)
+			}
+			buf.WriteByte(byte(n))
+			continue
+		}
+		buf.WriteByte(b)
+	}
+	return token{kind: tokName, val: buf.String()}, nil
+}
+
+// scanLitString consumes a PDF literal string `(...)`, honoring nested
+// balanced parentheses and the backslash escape sequences defined in
+// section 7.3.4.2 of the spec: \n \r \t \b \f \( \) \\, a line
+// continuation (backslash immediately followed by EOL, which is elided),
+// and up to three octal digits \ddd.
+func (lx *pdfLexer) scanLitString() (token, error) {
+	var buf bytes.Buffer
+	depth := 1
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			return token{}, fmt.Errorf("pdf lexer: unterminated literal string: %w", err)
+		}
+		switch b {
+		case '(':
+			depth++
+			buf.WriteByte(b)
+		case ')':
+			depth--
+			if depth == 0 {
+				return token{kind: tokLitString, val: buf.String()}, nil
+			}
+			buf.WriteByte(b)
+		case '\\':
+			eb, err := lx.readByte()
+			if err != nil {
+				return token{}, fmt.Errorf("pdf lexer: unterminated escape in literal string: %w", err)
+			}
+			switch eb {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case '(', ')', '\\':
+				buf.WriteByte(eb)
+			case '\r':
+				peek, err := lx.br.Peek(1)
+				if err == nil && len(peek) == 1 && peek[0] == '\n' {
+					lx.readByte()
+				}
+			case '\n':
+				// line continuation, nothing is emitted
+			default:
+				if eb >= '0' && eb <= '7' {
+					digits := []byte{eb}
+					for i := 0; i < 2; i++ {
+						peek, err := lx.br.Peek(1)
+						if err != nil || peek[0] < '0' || peek[0] > '7' {
+							break
+						}
+						d, _ := lx.readByte()
+						digits = append(digits, d)
+					}
+					n, err := strconv.ParseUint(string(digits), 8, 16)
+					if err != nil {
+						return token{}, fmt.Errorf("pdf lexer: bad octal escape: %w", err)
+					}
+					buf.WriteByte(byte(n))
+				} else {
+					// unknown escape: the backslash is ignored per spec
+					buf.WriteByte(eb)
+				}
+			}
+		default:
+			buf.WriteByte(b)
+		}
+	}
+}
+
+func (lx *pdfLexer) scanHexString() (token, error) {
+	var hex bytes.Buffer
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			return token{}, fmt.Errorf("pdf lexer: unterminated hex string: %w", err)
+		}
+		if b == '>' {
+			break
+		}
+		if isInByteArr(b, whiteSpaceChars) {
+			continue
+		}
+		hex.WriteByte(b)
+	}
+	if hex.Len()%2 == 1 {
+		hex.WriteByte('0')
+	}
+	raw := make([]byte, hex.Len()/2)
+	if _, err := fmt.Sscanf(hex.String(), "%x", &raw); err != nil {
+		for i := 0; i < len(raw); i++ {
+			n, err := strconv.ParseUint(hex.String()[i*2:i*2+2], 16, 8)
+			if err != nil {
+				return token{}, fmt.Errorf("pdf lexer: bad hex string: %w", err)
+			}
+			raw[i] = byte(n)
+		}
+	}
+	return token{kind: tokHexString, val: string(raw)}, nil
+}
+
+func (lx *pdfLexer) scanNumber() (token, error) {
+	var buf bytes.Buffer
+	isReal := false
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return token{}, err
+		}
+		if b == '.' {
+			isReal = true
+			buf.WriteByte(b)
+			continue
+		}
+		if (b >= '0' && b <= '9') || b == '+' || b == '-' {
+			buf.WriteByte(b)
+			continue
+		}
+		lx.unreadByte()
+		break
+	}
+	if isReal {
+		f, err := strconv.ParseFloat(buf.String(), 64)
+		if err != nil {
+			return token{}, fmt.Errorf("pdf lexer: bad real number %q: %w", buf.String(), err)
+		}
+		return token{kind: tokReal, val: f}, nil
+	}
+	n, err := strconv.ParseInt(buf.String(), 10, 64)
+	if err != nil {
+		return token{}, fmt.Errorf("pdf lexer: bad integer %q: %w", buf.String(), err)
+	}
+	return token{kind: tokInteger, val: n}, nil
+}
+
+func (lx *pdfLexer) scanKeyword() (token, error) {
+	var buf bytes.Buffer
+	for {
+		b, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return token{}, err
+		}
+		if isInByteArr(b, whiteSpaceChars) || isInByteArr(b, delimiterChars) {
+			lx.unreadByte()
+			break
+		}
+		buf.WriteByte(b)
+	}
+	if buf.Len() == 0 {
+		return token{}, errors.New("pdf lexer: unexpected character")
+	}
+	kw := buf.String()
+	if kw == "stream" {
+		// per spec the stream body begins after the EOL following the
+		// keyword: CRLF or LF alone, never CR alone.
+		peek, err := lx.br.Peek(1)
+		if err == nil && len(peek) == 1 && peek[0] == '\r' {
+			lx.readByte()
+		}
+		peek, err = lx.br.Peek(1)
+		if err == nil && len(peek) == 1 && peek[0] == '\n' {
+			lx.readByte()
+		}
+		return token{kind: tokStream, val: lx.offset}, nil
+	}
+	return token{kind: tokKeyword, val: kw}, nil
+}
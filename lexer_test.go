@@ -0,0 +1,61 @@
+package gofpdf
+
+import "testing"
+
+// TestLexerLiteralStrings exercises the escape handling documented on
+// scanLitString: octal runs, line continuations, and nested balanced
+// parentheses.
+func TestLexerLiteralStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "(hello)", "hello"},
+		{"nested parens", "(a(b)c)", "a(b)c"},
+		{"named escapes", `(\n\r\t\b\f\(\)\\)`, "\n\r\t\b\f()\\"},
+		{"octal escape", `(\101\102\103)`, "ABC"},
+		{"octal clamps at three digits", `(\1014)`, "A4"},
+		{"line continuation elided", "(a\\\nb)", "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lx := newPDFLexerBytes([]byte(tt.input))
+			tok, err := lx.Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if tok.kind != tokLitString {
+				t.Fatalf("kind = %v, want tokLitString", tok.kind)
+			}
+			if got := tok.val.(string); got != tt.want {
+				t.Errorf("val = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLexerNumbersAndKeywords exercises scanNumber/scanKeyword's object
+// kinds alongside the literal-string cases above, since they share the
+// same Next() dispatch.
+func TestLexerNumbersAndKeywords(t *testing.T) {
+	lx := newPDFLexerBytes([]byte("12 -3.5 true obj"))
+
+	tok, err := lx.Next()
+	if err != nil || tok.kind != tokInteger || tok.val.(int64) != 12 {
+		t.Fatalf("first token = %+v, err %v", tok, err)
+	}
+	tok, err = lx.Next()
+	if err != nil || tok.kind != tokReal || tok.val.(float64) != -3.5 {
+		t.Fatalf("second token = %+v, err %v", tok, err)
+	}
+	tok, err = lx.Next()
+	if err != nil || tok.kind != tokKeyword || tok.val.(string) != "true" {
+		t.Fatalf("third token = %+v, err %v", tok, err)
+	}
+	tok, err = lx.Next()
+	if err != nil || tok.kind != tokKeyword || tok.val.(string) != "obj" {
+		t.Fatalf("fourth token = %+v, err %v", tok, err)
+	}
+}
@@ -60,11 +60,13 @@ type pdfReader struct {
 	file          *os.File
 	recentReading []byte
 	cursorAt      int64
-	recentDict    map[string]interface{}
 	pdfVersion    byte
 	inStrEsc      bool
 	xrefOffset    int64
 	xrefTable     xref
+	lexer         *pdfLexer
+	objStmCache   map[int]*objStm
+	encrypt       *encryptInfo
 }
 
 var whiteSpaceChars = []byte{0, //Null
@@ -119,20 +121,23 @@ func (r *pdfReader) openFile(filePath string) error {
 		return err
 	}
 
-	r.recentDict = make(map[string]interface{})
-
 	return nil
 }
 
 type xref struct {
 	objStart, objEnd int
 	objTable         []xrefObjData
-	trailer          map[string]interface{}
+	trailer          Value
 }
 
 type xrefObjData struct {
 	offset, generationNumber, objNum int
 	inUse                            bool
+	// compressed marks an entry that lives inside an /ObjStm object
+	// stream rather than at a direct file offset; objStmNum/objStmIndex
+	// then locate it within that container.
+	compressed             bool
+	objStmNum, objStmIndex int
 }
 
 type objReference struct {
@@ -216,14 +221,87 @@ const (
 	trailerPart
 )
 
+// parseXRefTable loads r's xref table starting at r.xrefOffset, then
+// follows each section's /Prev entry to the previous incremental
+// update's xref section (classic table or stream, either may appear in
+// the same /Prev chain) until none remains. Sections are merged
+// newest-first: an entry for an object number already supplied by a
+// newer section is left alone, so a free (type-0) entry in a later
+// revision correctly shadows an in-use entry from an earlier one, and
+// the trailer of the newest section (the one at r.xrefOffset) wins.
 func (r *pdfReader) parseXRefTable() error {
-	err := r.pdfReadAt(r.xrefOffset, false, false)
+	seen := make(map[int64]bool)
+	offset := r.xrefOffset
+	var sections []xref
+
+	for offset != 0 && !seen[offset] {
+		seen[offset] = true
+
+		isStream, err := r.isXRefStreamAt(offset)
+		if err != nil {
+			return err
+		}
+
+		var sec xref
+		if isStream {
+			sec, err = r.parseXRefStreamAt(offset)
+		} else {
+			sec, err = r.parseClassicXRefAt(offset)
+		}
+		if err != nil {
+			return err
+		}
+		sections = append(sections, sec)
+
+		prev := sec.trailer.Key("Prev")
+		if prev.Kind() != Integer {
+			break
+		}
+		offset = prev.Int64()
+	}
+
+	r.xrefTable = mergeXRefSections(sections)
+	return nil
+}
+
+// mergeXRefSections combines sections (newest first, the order
+// parseXRefTable discovers them in following /Prev) into a single
+// xref: the first section to mention an object number wins, and the
+// newest section's trailer is kept as the merged trailer.
+func mergeXRefSections(sections []xref) xref {
+	var merged xref
+	seen := make(map[int]bool)
+	for i, sec := range sections {
+		if i == 0 {
+			merged.trailer = sec.trailer
+			merged.objStart = sec.objStart
+			merged.objEnd = sec.objEnd
+		}
+		for _, row := range sec.objTable {
+			if seen[row.objNum] {
+				continue
+			}
+			seen[row.objNum] = true
+			merged.objTable = append(merged.objTable, row)
+		}
+	}
+	return merged
+}
+
+// parseClassicXRefAt parses one classic `xref`/`trailer` section
+// beginning at offset into a standalone xref, leaving r.xrefTable
+// untouched so parseXRefTable can merge it together with any sections
+// reachable through /Prev.
+func (r *pdfReader) parseClassicXRefAt(offset int64) (xref, error) {
+	var sec xref
+
+	err := r.pdfReadAt(offset, false, false)
 	if err != nil {
-		return err
+		return xref{}, err
 	}
 
 	if bytes.Compare(r.recentReading, []byte("xref")) != 0 {
-		errors.New("malformed pdf: xref offset was incorrect")
+		return xref{}, errors.New("malformed pdf: xref offset was incorrect")
 	}
 
 	part := objNumStart
@@ -233,7 +311,7 @@ func (r *pdfReader) parseXRefTable() error {
 	for bytes.Compare(r.recentReading, []byte("startxref")) != 0 {
 		err = r.pdfReadAt(r.cursorAt, false, false)
 		if err != nil {
-			return err
+			return xref{}, err
 		}
 		if bytes.Compare(r.recentReading, []byte("trailer")) == 0 {
 			part = trailerPart
@@ -242,17 +320,17 @@ func (r *pdfReader) parseXRefTable() error {
 		case objNumStart:
 			start, err := strconv.Atoi(string(r.recentReading))
 			if err != nil {
-				return err
+				return xref{}, err
 			}
-			r.xrefTable.objStart = start
-			objNum = r.xrefTable.objStart
+			sec.objStart = start
+			objNum = sec.objStart
 			part = objNumEnd
 		case objNumEnd:
 			end, err := strconv.Atoi(string(r.recentReading))
 			if err != nil {
-				return err
+				return xref{}, err
 			}
-			r.xrefTable.objEnd = end
+			sec.objEnd = end
 			part = objTabCol1
 		case objTabCol1:
 			objRow = xrefObjData{}
@@ -260,14 +338,14 @@ func (r *pdfReader) parseXRefTable() error {
 			objNum++
 			offset, err := strconv.Atoi(string(r.recentReading))
 			if err != nil {
-				return err
+				return xref{}, err
 			}
 			objRow.offset = offset
 			part = objTabCol2
 		case objTabCol2:
 			genNum, err := strconv.Atoi(string(r.recentReading))
 			if err != nil {
-				return err
+				return xref{}, err
 			}
 			objRow.generationNumber = genNum
 			part = objTabCol3
@@ -277,112 +355,165 @@ func (r *pdfReader) parseXRefTable() error {
 			} else if bytes.Compare(r.recentReading, []byte("n")) == 0 {
 				objRow.inUse = true
 			} else {
-				return errors.New("malformed xref table third col can be only f or n")
+				return xref{}, errors.New("malformed xref table third col can be only f or n")
 			}
-			r.xrefTable.objTable = append(r.xrefTable.objTable, objRow)
+			sec.objTable = append(sec.objTable, objRow)
 			part = objTabCol1
 		case trailerPart:
-			r.pdfReadAt(r.cursorAt, false, false)
-			err := r.parsePDFDict()
+			r.lexer = newPDFLexer(r.file, r.cursorAt, r.pdfSize()-r.cursorAt)
+			dict, err := r.parsePDFDict()
+			if err != nil {
+				return xref{}, err
+			}
+			sec.trailer = dict
+
+			// The lexer consumed bytes the byte-scanner above doesn't know
+			// about; resync r.cursorAt and r.recentReading to whatever
+			// token follows the dictionary (normally "startxref") so the
+			// loop condition above sees it instead of re-scanning the
+			// dictionary's own closing ">>".
+			tok, err := r.lexer.Next()
 			if err != nil {
-				return err
+				return xref{}, err
 			}
-			r.xrefTable.trailer = r.recentDict
+			if tok.kind == tokKeyword {
+				r.recentReading = []byte(tok.val.(string))
+			}
+			r.cursorAt = r.lexer.offset
 		}
 	}
-	return nil
+	return sec, nil
 }
 
-func (r *pdfReader) parsePDFDict() error {
-	if !bytes.HasPrefix(r.recentReading, []byte("<")) {
-		return errors.New("expected pdf dictionary")
+// parsePDFDict consumes a `<< ... >>` dictionary from r.lexer, recursing
+// into parsePDFObject for each value so nested arrays and dictionaries are
+// handled uniformly, and returns it as a Dict Value.
+func (r *pdfReader) parsePDFDict() (Value, error) {
+	tok, err := r.lexer.Next()
+	if err != nil {
+		return Value{}, err
 	}
-
-	r.pdfReadAt(r.cursorAt, false, false)
-	if !bytes.HasPrefix(r.recentReading, []byte("<")) {
-		return errors.New("expected pdf dictionary")
+	if tok.kind != tokDictStart {
+		return Value{}, errors.New("expected pdf dictionary")
 	}
 
-	r.pdfReadAt(r.cursorAt, false, false)
-	atKey := true
-	cK := ""
-	for !bytes.HasSuffix(r.recentReading, []byte(">")) {
-		if atKey {
-			key, err := r.parsePDFObject()
-			if err != nil {
-				return err
-			}
-			cK = key.(string)
-			atKey = false
-		} else {
-			val, err := r.parsePDFObject()
-			if err != nil {
-				return err
-			}
-			r.recentDict[cK] = val
-			atKey = true
+	dict := make(map[string]Value)
+	for {
+		tok, err = r.lexer.Next()
+		if err != nil {
+			return Value{}, err
+		}
+		if tok.kind == tokDictEnd {
+			return newValue(r, Dict, dict), nil
+		}
+		if tok.kind != tokName {
+			return Value{}, fmt.Errorf("pdf: expected dictionary key, got token kind %d", tok.kind)
+		}
+		key := tok.val.(string)
+
+		val, err := r.parsePDFObject()
+		if err != nil {
+			return Value{}, err
 		}
-		r.pdfReadAt(r.cursorAt, false, false)
+		dict[key] = val
 	}
+}
 
-	r.pdfReadAt(r.cursorAt, false, false)
-	if !bytes.HasPrefix(r.recentReading, []byte(">")) {
-		return errors.New("pdf dictionary ending malformed")
+// parsePDFObject reads the next token from r.lexer and decodes it into a
+// Value: Name/String for Name/LitString/HexString tokens, Integer/Real
+// for numbers, Bool, Null, an indirect-reference Value for `N G R`,
+// Array, nested Dict, and Stream for a dictionary followed by the
+// `stream` keyword.
+func (r *pdfReader) parsePDFObject() (Value, error) {
+	tok, err := r.lexer.Next()
+	if err != nil {
+		return Value{}, err
 	}
-	r.pdfReadAt(r.cursorAt, false, false)
-	return nil
+	return r.parsePDFObjectFrom(tok)
 }
 
-func (r *pdfReader) parsePDFObject() (interface{}, error) {
-	switch {
-	case bytes.HasPrefix(r.recentReading, []byte("/")):
-		return string(r.recentReading[1:len(r.recentReading)]), nil
-	case byte('0') <= r.recentReading[0] && byte('9') >= r.recentReading[0]:
-		peek, err := r.peek()
+func (r *pdfReader) parsePDFObjectFrom(tok token) (Value, error) {
+	switch tok.kind {
+	case tokName:
+		return newValue(r, Name, tok.val.(string)), nil
+	case tokLitString, tokHexString:
+		return newValue(r, String, tok.val.(string)), nil
+	case tokReal:
+		return newValue(r, Real, tok.val.(float64)), nil
+	case tokInteger:
+		num := tok.val.(int64)
+
+		genTok, err := r.lexer.Next()
 		if err != nil {
-			return nil, err
+			return Value{}, err
 		}
-		if isInByteArr(peek[0], delimiterChars) {
-			if bytes.ContainsRune(r.recentReading, '.') {
-				return strconv.ParseFloat(string(r.recentReading), 32)
-			}
-			return strconv.Atoi(string(r.recentReading))
+		if genTok.kind != tokInteger {
+			r.lexer.pushBack(genTok)
+			return newValue(r, Integer, num), nil
 		}
-		var retObj objReference
-		num, err := strconv.Atoi(string(r.recentReading))
+
+		rTok, err := r.lexer.Next()
 		if err != nil {
-			return nil, err
+			return Value{}, err
+		}
+		if rTok.kind == tokKeyword && rTok.val.(string) == "R" {
+			return newRefValue(r, objReference{objNum: int(num), generationNum: int(genTok.val.(int64))}), nil
 		}
-		retObj.objNum = num
-		r.pdfReadAt(r.cursorAt, false, false)
-		gen, err := strconv.Atoi(string(r.recentReading))
+		r.lexer.pushBack(rTok)
+		r.lexer.pushBack(genTok)
+		return newValue(r, Integer, num), nil
+	case tokKeyword:
+		switch tok.val.(string) {
+		case "true":
+			return newValue(r, Bool, true), nil
+		case "false":
+			return newValue(r, Bool, false), nil
+		case "null":
+			return newValue(r, Null, nil), nil
+		}
+		return Value{}, fmt.Errorf("pdf: unexpected keyword %q", tok.val)
+	case tokArrayStart:
+		var arr []Value
+		for {
+			t, err := r.lexer.Next()
+			if err != nil {
+				return Value{}, err
+			}
+			if t.kind == tokArrayEnd {
+				return newValue(r, Array, arr), nil
+			}
+			v, err := r.parsePDFObjectFrom(t)
+			if err != nil {
+				return Value{}, err
+			}
+			arr = append(arr, v)
+		}
+	case tokDictStart:
+		r.lexer.pushBack(tok)
+		dictVal, err := r.parsePDFDict()
 		if err != nil {
-			return nil, err
+			return Value{}, err
 		}
-		retObj.generationNum = gen
-		r.pdfReadAt(r.cursorAt, false, false)
-		return retObj, nil
-	}
-
-	return nil, nil
-}
 
-func (r *pdfReader) peek() ([]byte, error) {
-	saveLoc := r.cursorAt
-	saveRead := r.recentReading
+		nextTok, err := r.lexer.Next()
+		if err != nil {
+			return Value{}, err
+		}
+		if nextTok.kind != tokStream {
+			r.lexer.pushBack(nextTok)
+			return dictVal, nil
+		}
 
-	err := r.pdfReadAt(r.cursorAt, false, false)
-	if err != nil {
-		return nil, err
+		dict, _ := dictVal.dictData()
+		sv := &streamValue{
+			dict:   dict,
+			file:   r.file,
+			offset: nextTok.val.(int64),
+			length: dictVal.Key("Length").Int64(),
+		}
+		return newValue(r, Stream, sv), nil
+	case tokStream:
+		return Value{}, errors.New("pdf: stream keyword outside of a dictionary object")
 	}
-
-	retRead := r.recentReading
-	r.cursorAt = saveLoc
-	r.recentReading = saveRead
-	return retRead, nil
-}
-
-func (r *pdfReader) buildFpdf() (f *Fpdf, err error) {
-	fmt.Println(r.xrefTable)
-	return f, err
+	return Value{}, fmt.Errorf("pdf: unexpected token kind %d", tok.kind)
 }
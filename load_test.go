@@ -0,0 +1,62 @@
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildIncrementalFixture writes a tiny two-revision PDF to path: object 1
+// starts out holding /Foo (original), then an appended incremental update
+// rewrites object 1 to hold /Foo (updated) and chains its trailer's /Prev
+// back to the original xref section.
+func buildIncrementalFixture(t *testing.T, path string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	obj1Offset := buf.Len()
+	buf.WriteString("1 0 obj\n<< /Foo (original) >>\nendobj\n")
+
+	xref1Offset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 2\n0000000000 65535 f \n%010d 00000 n \ntrailer\n<< /Size 2 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", obj1Offset, xref1Offset)
+
+	obj1v2Offset := buf.Len()
+	buf.WriteString("1 0 obj\n<< /Foo (updated) >>\nendobj\n")
+
+	xref2Offset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 2\n0000000000 65535 f \n%010d 00000 n \ntrailer\n<< /Size 2 /Root 1 0 R /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", obj1v2Offset, xref1Offset, xref2Offset)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+}
+
+// TestParseXRefTableFollowsPrev exercises an incrementally updated PDF:
+// the newest xref section's entry for object 1 must win over the one the
+// base revision started with.
+func TestParseXRefTableFollowsPrev(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incremental.pdf")
+	buildIncrementalFixture(t, path)
+
+	var r pdfReader
+	if err := r.openFile(path); err != nil {
+		t.Fatalf("openFile: %v", err)
+	}
+	defer r.closeFile()
+
+	if err := r.parseXRefTable(); err != nil {
+		t.Fatalf("parseXRefTable: %v", err)
+	}
+
+	obj, err := r.getObject(objReference{objNum: 1})
+	if err != nil {
+		t.Fatalf("getObject: %v", err)
+	}
+	if got := obj.Key("Foo").RawString(); got != "updated" {
+		t.Errorf("object 1 /Foo = %q, want %q", got, "updated")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"fmt"
+	"io"
+)
+
+// objStm is a decoded /Type /ObjStm container: the objects packed inside
+// it, keyed by their position (not their object number, since the same
+// object number may appear compressed in more than one revision).
+type objStm struct {
+	nums    []int
+	offsets []int64
+	body    []byte
+	first   int64
+}
+
+// getObject is the canonical way to dereference ref: it looks ref up in
+// r's xref table and returns the object found there as a Value, whether
+// that entry is a direct (type-1) file offset or a compressed (type-2)
+// entry inside an /ObjStm container. Value.Key and Value.Index call
+// through here automatically when they follow an indirect reference, so
+// callers never need to care which form was on disk.
+func (r *pdfReader) getObject(ref objReference) (Value, error) {
+	for _, row := range r.xrefTable.objTable {
+		if row.objNum != ref.objNum {
+			continue
+		}
+		if !row.inUse {
+			return Value{}, fmt.Errorf("pdf: object %d %d R is free", ref.objNum, ref.generationNum)
+		}
+		if row.compressed {
+			return r.getCompressedObject(row.objStmNum, row.objStmIndex)
+		}
+		return r.parseObjectAt(int64(row.offset))
+	}
+	return Value{}, fmt.Errorf("pdf: unresolved object reference %d %d R", ref.objNum, ref.generationNum)
+}
+
+// parseObjectAt parses the `N G obj ... endobj` object whose header
+// begins at offset in the file and returns its body as a Value.
+func (r *pdfReader) parseObjectAt(offset int64) (Value, error) {
+	lx := newPDFLexer(r.file, offset, r.pdfSize()-offset)
+
+	numTok, err := lx.Next()
+	if err != nil {
+		return Value{}, err
+	}
+	genTok, err := lx.Next()
+	if err != nil {
+		return Value{}, err
+	}
+	objTok, err := lx.Next()
+	if err != nil {
+		return Value{}, err
+	}
+	if numTok.kind != tokInteger || genTok.kind != tokInteger ||
+		objTok.kind != tokKeyword || objTok.val.(string) != "obj" {
+		return Value{}, fmt.Errorf("pdf: malformed object header at offset %d", offset)
+	}
+
+	saved := r.lexer
+	r.lexer = lx
+	val, err := r.parsePDFObject()
+	r.lexer = saved
+	if err != nil {
+		return Value{}, err
+	}
+
+	if r.encrypt != nil {
+		val = r.decryptValueTree(val, int(numTok.val.(int64)), int(genTok.val.(int64)))
+	}
+	return val, nil
+}
+
+// getCompressedObject returns the index'th object packed inside the
+// /ObjStm numbered objStmNum. Objects compressed this way are never
+// individually encrypted, even in an encrypted document: loadObjStm
+// already decrypted the container stream itself, so no further
+// decryption is applied here.
+func (r *pdfReader) getCompressedObject(objStmNum, index int) (Value, error) {
+	stm, err := r.loadObjStm(objStmNum)
+	if err != nil {
+		return Value{}, err
+	}
+	if index < 0 || index >= len(stm.offsets) {
+		return Value{}, fmt.Errorf("pdf: object stream %d has no entry %d", objStmNum, index)
+	}
+	start := stm.first + stm.offsets[index]
+	if start < 0 || start > int64(len(stm.body)) {
+		return Value{}, fmt.Errorf("pdf: object stream %d entry %d offset out of range", objStmNum, index)
+	}
+
+	saved := r.lexer
+	r.lexer = newPDFLexerBytes(stm.body[start:])
+	val, err := r.parsePDFObject()
+	r.lexer = saved
+	return val, err
+}
+
+// loadObjStm decodes the /ObjStm object numbered objNum and caches the
+// result, since a single object stream commonly packs dozens of objects
+// that get dereferenced one at a time.
+func (r *pdfReader) loadObjStm(objNum int) (*objStm, error) {
+	if stm, ok := r.objStmCache[objNum]; ok {
+		return stm, nil
+	}
+
+	val, err := r.getObject(objReference{objNum: objNum})
+	if err != nil {
+		return nil, fmt.Errorf("pdf: loading object stream %d: %w", objNum, err)
+	}
+	if val.Kind() != Stream {
+		return nil, fmt.Errorf("pdf: object %d is not an object stream", objNum)
+	}
+
+	n := int(val.Key("N").Int64())
+	first := val.Key("First").Int64()
+
+	body, err := io.ReadAll(val.Reader())
+	if err != nil {
+		return nil, fmt.Errorf("pdf: decoding object stream %d: %w", objNum, err)
+	}
+
+	stm := &objStm{body: body, first: first}
+	header := newPDFLexerBytes(body)
+	for i := 0; i < n; i++ {
+		numTok, err := header.Next()
+		if err != nil {
+			return nil, err
+		}
+		offTok, err := header.Next()
+		if err != nil {
+			return nil, err
+		}
+		if numTok.kind != tokInteger || offTok.kind != tokInteger {
+			return nil, fmt.Errorf("pdf: malformed header in object stream %d", objNum)
+		}
+		stm.nums = append(stm.nums, int(numTok.val.(int64)))
+		stm.offsets = append(stm.offsets, offTok.val.(int64))
+	}
+
+	if r.objStmCache == nil {
+		r.objStmCache = make(map[int]*objStm)
+	}
+	r.objStmCache[objNum] = stm
+	return stm, nil
+}
@@ -0,0 +1,130 @@
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildObjStmFixture writes a minimal PDF whose cross-reference stream
+// points two objects (3 and 4) at compressed entries inside a single
+// /ObjStm object (2), packing literal strings (Foo) and (Bar).
+func buildObjStmFixture(t *testing.T, path string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	// header: "objNum offset" pairs relative to /First, then the packed
+	// object bodies themselves.
+	header := "3 0 4 6"
+	body := header + "\n" + "(Foo)\n(Bar)"
+
+	objStmOffset := int64(buf.Len())
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /ObjStm /N 2 /First %d /Length %d >>\nstream\n", len(header)+1, len(body))
+	buf.WriteString(body)
+	buf.WriteString("\nendstream\nendobj\n")
+
+	xrefOffset := int64(buf.Len())
+	// W = [1, 2, 1]. Index [0 1 2 3] covers object 0 (free), then
+	// objects 2-4 (the container and its two compressed entries).
+	records := []byte{
+		0, 0, 0, 0, // object 0: free
+		1, byte(objStmOffset >> 8), byte(objStmOffset), 0, // object 2: the ObjStm, direct
+		2, 0, 2, 0, // object 3: compressed in stream 2, index 0
+		2, 0, 2, 1, // object 4: compressed in stream 2, index 1
+	}
+	fmt.Fprintf(&buf, "5 0 obj\n<< /Type /XRef /Size 5 /W [1 2 1] /Index [0 1 2 3] /Length %d >>\nstream\n", len(records))
+	buf.Write(records)
+	buf.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+}
+
+// TestGetCompressedObject checks that objects packed inside an /ObjStm
+// are retrieved by index through the same getObject path used for
+// direct objects.
+func TestGetCompressedObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "objstm.pdf")
+	buildObjStmFixture(t, path)
+
+	var r pdfReader
+	if err := r.openFile(path); err != nil {
+		t.Fatalf("openFile: %v", err)
+	}
+	defer r.closeFile()
+
+	if err := r.parseXRefTable(); err != nil {
+		t.Fatalf("parseXRefTable: %v", err)
+	}
+
+	obj3, err := r.getObject(objReference{objNum: 3})
+	if err != nil {
+		t.Fatalf("getObject(3): %v", err)
+	}
+	if got := obj3.RawString(); got != "Foo" {
+		t.Errorf("object 3 = %q, want %q", got, "Foo")
+	}
+
+	obj4, err := r.getObject(objReference{objNum: 4})
+	if err != nil {
+		t.Fatalf("getObject(4): %v", err)
+	}
+	if got := obj4.RawString(); got != "Bar" {
+		t.Errorf("object 4 = %q, want %q", got, "Bar")
+	}
+}
+
+// TestGetCompressedObjectOutOfRangeOffset checks that a malformed
+// /ObjStm whose header claims an offset past the end of the decoded
+// stream body returns an error instead of panicking on the slice
+// expression in getCompressedObject.
+func TestGetCompressedObjectOutOfRangeOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "objstm-bad.pdf")
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	// header claims object 3 lives at offset 999999 within the body,
+	// far past the body's actual length.
+	header := "3 999999"
+	body := header + "\n"
+
+	objStmOffset := int64(buf.Len())
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /ObjStm /N 1 /First %d /Length %d >>\nstream\n", len(header)+1, len(body))
+	buf.WriteString(body)
+	buf.WriteString("\nendstream\nendobj\n")
+
+	xrefOffset := int64(buf.Len())
+	records := []byte{
+		0, 0, 0, 0, // object 0: free
+		1, byte(objStmOffset >> 8), byte(objStmOffset), 0, // object 2: the ObjStm, direct
+	}
+	fmt.Fprintf(&buf, "3 0 obj\n<< /Type /XRef /Size 3 /W [1 2 1] /Index [0 1 2 1] /Length %d >>\nstream\n", len(records))
+	buf.Write(records)
+	buf.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	var r pdfReader
+	if err := r.openFile(path); err != nil {
+		t.Fatalf("openFile: %v", err)
+	}
+	defer r.closeFile()
+
+	if err := r.parseXRefTable(); err != nil {
+		t.Fatalf("parseXRefTable: %v", err)
+	}
+
+	if _, err := r.getCompressedObject(2, 0); err == nil {
+		t.Fatalf("getCompressedObject with an out-of-range header offset = nil error, want an error")
+	}
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Noofbiz/gofpdf/filter"
+)
+
+// Kind identifies what a Value holds. It mirrors the object types defined
+// by the PDF spec (section 7.3) plus Null for the PDF null object.
+type Kind int
+
+// The Kind values a Value can report from Kind().
+const (
+	Null Kind = iota
+	Integer
+	Real
+	Bool
+	Name
+	String
+	Dict
+	Array
+	Stream
+)
+
+// Value is a single PDF object, resolved lazily against its pdfReader
+// if it's an indirect reference. Accessors called on the wrong Kind
+// return the zero value rather than panicking, so a chain like
+// v.Key("Root").Key("Pages").Key("Kids").Index(0) is always safe to write.
+type Value struct {
+	r     *pdfReader
+	kind  Kind
+	data  interface{}
+	isRef bool
+	ref   objReference
+}
+
+func newValue(r *pdfReader, kind Kind, data interface{}) Value {
+	return Value{r: r, kind: kind, data: data}
+}
+
+func newRefValue(r *pdfReader, ref objReference) Value {
+	return Value{r: r, isRef: true, ref: ref}
+}
+
+// resolve returns the concrete Value that v refers to, following an
+// indirect reference through the owning pdfReader's xref table if
+// necessary. A reference that cannot be resolved resolves to the zero
+// Value (Kind Null).
+func (v Value) resolve() Value {
+	if !v.isRef {
+		return v
+	}
+	if v.r == nil {
+		return Value{}
+	}
+	resolved, err := v.r.getObject(v.ref)
+	if err != nil {
+		return Value{}
+	}
+	return resolved
+}
+
+// Kind reports what kind of PDF object v holds, resolving an indirect
+// reference first if necessary.
+func (v Value) Kind() Kind {
+	return v.resolve().kind
+}
+
+// Int64 returns v's value as an int64. It returns 0 if v is not an
+// Integer (or a Real, which is also accepted and truncated).
+func (v Value) Int64() int64 {
+	rv := v.resolve()
+	switch n := rv.data.(type) {
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// Float64 returns v's value as a float64. It returns 0 if v is neither a
+// Real nor an Integer.
+func (v Value) Float64() float64 {
+	rv := v.resolve()
+	switch n := rv.data.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// Bool returns v's value if v is a Bool, and false otherwise.
+func (v Value) Bool() bool {
+	rv := v.resolve()
+	b, _ := rv.data.(bool)
+	return b
+}
+
+// Name returns v's value if v is a Name (without the leading `/`), and ""
+// otherwise.
+func (v Value) Name() string {
+	rv := v.resolve()
+	if rv.kind != Name {
+		return ""
+	}
+	s, _ := rv.data.(string)
+	return s
+}
+
+// RawString returns v's decoded bytes if v is a String (literal or hex),
+// and "" otherwise.
+func (v Value) RawString() string {
+	rv := v.resolve()
+	if rv.kind != String {
+		return ""
+	}
+	s, _ := rv.data.(string)
+	return s
+}
+
+// Key looks up name in v, returning the zero Value if v is not a Dict (or
+// a Stream, whose dictionary is searched) or name is absent.
+func (v Value) Key(name string) Value {
+	rv := v.resolve()
+	dict, ok := rv.dictData()
+	if !ok {
+		return Value{}
+	}
+	val, ok := dict[name]
+	if !ok {
+		return Value{}
+	}
+	return val
+}
+
+// Index returns the i'th element of v, returning the zero Value if v is
+// not an Array or i is out of range.
+func (v Value) Index(i int) Value {
+	rv := v.resolve()
+	if rv.kind != Array {
+		return Value{}
+	}
+	arr, ok := rv.data.([]Value)
+	if !ok || i < 0 || i >= len(arr) {
+		return Value{}
+	}
+	return arr[i]
+}
+
+// Len returns the number of elements in v if v is an Array, the number
+// of keys if v is a Dict, or the number of bytes if v is a String. It
+// returns 0 for any other Kind.
+func (v Value) Len() int {
+	rv := v.resolve()
+	switch rv.kind {
+	case Array:
+		arr, _ := rv.data.([]Value)
+		return len(arr)
+	case Dict, Stream:
+		dict, _ := rv.dictData()
+		return len(dict)
+	case String:
+		s, _ := rv.data.(string)
+		return len(s)
+	}
+	return 0
+}
+
+// dictData extracts the underlying dictionary from a Dict or Stream
+// Value.
+func (v Value) dictData() (map[string]Value, bool) {
+	switch d := v.data.(type) {
+	case map[string]Value:
+		return d, true
+	case *streamValue:
+		return d.dict, true
+	}
+	return nil, false
+}
+
+// ErrUnsupported is wrapped in the error that reading from Value.Reader's
+// result reports when a Stream names a filter not registered in the
+// filter package.
+var ErrUnsupported = errors.New("gofpdf: unsupported stream filter")
+
+// streamValue is the concrete payload of a Stream Value: its dictionary
+// plus the file range holding the raw (still filtered) stream body. The
+// range is reopened as a fresh io.SectionReader on every Reader() call
+// since a Value may be read more than once.
+//
+// encrypted, encObjNum, encGen and encCFM are set by decryptValueTree for
+// streams belonging to an encrypted document, and decrypted before the
+// declared /Filter chain runs.
+type streamValue struct {
+	dict           map[string]Value
+	file           *os.File
+	offset, length int64
+	encrypted      bool
+	encObjNum      int
+	encGen         int
+	encCFM         string
+}
+
+// Reader returns a reader over v's decoded stream bytes, running them
+// through the filter chain named by the stream dictionary's /Filter
+// entry. If v is not a Stream, or its filter chain cannot be set up, the
+// returned reader's Read reports that error.
+func (v Value) Reader() io.ReadCloser {
+	rv := v.resolve()
+	sv, ok := rv.data.(*streamValue)
+	if rv.kind != Stream || !ok {
+		return io.NopCloser(errReader{errors.New("gofpdf: Reader called on non-Stream Value")})
+	}
+	rc, err := rv.r.decodeStream(rv, sv)
+	if err != nil {
+		return io.NopCloser(errReader{err})
+	}
+	return rc
+}
+
+// errReader is an io.Reader that always fails with err, used so Value.Reader
+// can report a problem without changing its signature to return an error.
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
+
+// decodeStream runs sv's raw bytes through the filter chain named by v's
+// dictionary's /Filter entry, which is either a single Name or an Array
+// of Names each paired positionally with an entry of /DecodeParms.
+func (r *pdfReader) decodeStream(v Value, sv *streamValue) (io.ReadCloser, error) {
+	var rdr io.Reader
+	if sv.encrypted {
+		raw := make([]byte, sv.length)
+		if _, err := sv.file.ReadAt(raw, sv.offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		rdr = bytes.NewReader(r.decryptBytes(raw, sv.encObjNum, sv.encGen, sv.encCFM))
+	} else {
+		rdr = io.NewSectionReader(sv.file, sv.offset, sv.length)
+	}
+
+	filters, parmsList, err := streamFilterChain(v)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range filters {
+		f, ok := filter.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupported, name)
+		}
+		rdr, err = f.Decode(rdr, parmsFromValue(parmsList[i]))
+		if err != nil {
+			return nil, fmt.Errorf("gofpdf: decoding stream filter %q: %w", name, err)
+		}
+	}
+	return io.NopCloser(rdr), nil
+}
+
+// streamFilterChain reads v's /Filter and /DecodeParms entries into
+// parallel slices, one element per filter stage. A Value with no
+// /Filter entry decodes to an empty chain (the stream is stored
+// uncompressed).
+func streamFilterChain(v Value) (names []string, parmsList []Value, err error) {
+	filterVal := v.Key("Filter")
+	parmsVal := v.Key("DecodeParms")
+
+	switch filterVal.Kind() {
+	case Null:
+		return nil, nil, nil
+	case Name:
+		return []string{filterVal.Name()}, []Value{parmsVal}, nil
+	case Array:
+		for i := 0; i < filterVal.Len(); i++ {
+			names = append(names, filterVal.Index(i).Name())
+			if parmsVal.Kind() == Array {
+				parmsList = append(parmsList, parmsVal.Index(i))
+			} else {
+				parmsList = append(parmsList, Value{})
+			}
+		}
+		return names, parmsList, nil
+	}
+	return nil, nil, fmt.Errorf("gofpdf: unexpected /Filter kind %d", filterVal.Kind())
+}
+
+// parmsFromValue converts a /DecodeParms dictionary Value into the
+// filter.Params map the filter subpackage expects, since that package
+// cannot import Value without creating an import cycle.
+func parmsFromValue(v Value) filter.Params {
+	params := filter.Params{}
+	dict, ok := v.dictData()
+	if !ok {
+		return params
+	}
+	for k, val := range dict {
+		switch val.Kind() {
+		case Integer:
+			params[k] = val.Int64()
+		case Real:
+			params[k] = val.Float64()
+		case Name:
+			params[k] = val.Name()
+		case Bool:
+			params[k] = val.Bool()
+		}
+	}
+	return params
+}
@@ -0,0 +1,117 @@
+package gofpdf
+
+import "testing"
+
+// TestValueAccessorsOnWrongKindReturnZero exercises Value's central
+// contract: an accessor called on the wrong Kind returns that
+// accessor's zero value instead of panicking, so chains like
+// v.Key("Root").Key("Pages") are always safe to write.
+func TestValueAccessorsOnWrongKindReturnZero(t *testing.T) {
+	dict := newValue(nil, Dict, map[string]Value{"Foo": newValue(nil, Integer, int64(1))})
+	array := newValue(nil, Array, []Value{newValue(nil, Integer, int64(1))})
+	integer := newValue(nil, Integer, int64(42))
+	real := newValue(nil, Real, 4.2)
+	boolean := newValue(nil, Bool, true)
+	name := newValue(nil, Name, "Foo")
+	str := newValue(nil, String, "bar")
+	null := Value{}
+
+	if got := dict.Int64(); got != 0 {
+		t.Errorf("Dict.Int64() = %d, want 0", got)
+	}
+	if got := dict.Float64(); got != 0 {
+		t.Errorf("Dict.Float64() = %v, want 0", got)
+	}
+	if got := integer.Bool(); got != false {
+		t.Errorf("Integer.Bool() = %v, want false", got)
+	}
+	if got := integer.Name(); got != "" {
+		t.Errorf("Integer.Name() = %q, want \"\"", got)
+	}
+	if got := integer.RawString(); got != "" {
+		t.Errorf("Integer.RawString() = %q, want \"\"", got)
+	}
+	if got := array.Key("Foo"); got.Kind() != Null {
+		t.Errorf("Array.Key() = %+v, want the zero Value", got)
+	}
+	if got := integer.Key("Foo"); got.Kind() != Null {
+		t.Errorf("Integer.Key() = %+v, want the zero Value", got)
+	}
+	if got := dict.Index(0); got.Kind() != Null {
+		t.Errorf("Dict.Index() = %+v, want the zero Value", got)
+	}
+	if got := array.Index(-1); got.Kind() != Null {
+		t.Errorf("Array.Index(-1) = %+v, want the zero Value", got)
+	}
+	if got := array.Index(5); got.Kind() != Null {
+		t.Errorf("Array.Index(5) (out of range) = %+v, want the zero Value", got)
+	}
+	if got := integer.Len(); got != 0 {
+		t.Errorf("Integer.Len() = %d, want 0", got)
+	}
+	if got := null.Kind(); got != Null {
+		t.Errorf("zero Value.Kind() = %v, want Null", got)
+	}
+	if got := null.Key("Foo"); got.Kind() != Null {
+		t.Errorf("zero Value.Key() = %+v, want the zero Value", got)
+	}
+
+	// Sanity check the right-Kind paths alongside the wrong-Kind ones,
+	// so a future change that breaks both directions at once is caught.
+	if got := integer.Int64(); got != 42 {
+		t.Errorf("Integer.Int64() = %d, want 42", got)
+	}
+	if got := real.Float64(); got != 4.2 {
+		t.Errorf("Real.Float64() = %v, want 4.2", got)
+	}
+	if got := boolean.Bool(); got != true {
+		t.Errorf("Bool.Bool() = %v, want true", got)
+	}
+	if got := name.Name(); got != "Foo" {
+		t.Errorf("Name.Name() = %q, want %q", got, "Foo")
+	}
+	if got := str.RawString(); got != "bar" {
+		t.Errorf("String.RawString() = %q, want %q", got, "bar")
+	}
+	if got := dict.Key("Foo").Int64(); got != 1 {
+		t.Errorf("Dict.Key(\"Foo\").Int64() = %d, want 1", got)
+	}
+	if got := array.Index(0).Int64(); got != 1 {
+		t.Errorf("Array.Index(0).Int64() = %d, want 1", got)
+	}
+	if got := array.Len(); got != 1 {
+		t.Errorf("Array.Len() = %d, want 1", got)
+	}
+	if got := dict.Len(); got != 1 {
+		t.Errorf("Dict.Len() = %d, want 1", got)
+	}
+	if got := str.Len(); got != 3 {
+		t.Errorf("String.Len() = %d, want 3", got)
+	}
+}
+
+// TestValueChainedAccessOnMalformedDocument checks that a long accessor
+// chain over an unexpected document shape (here, a Dict where an Array
+// was expected) never panics and simply bottoms out at the zero Value.
+func TestValueChainedAccessOnMalformedDocument(t *testing.T) {
+	root := newValue(nil, Dict, map[string]Value{
+		"Pages": newValue(nil, Dict, map[string]Value{}),
+	})
+	got := root.Key("Pages").Key("Kids").Index(0).Key("MediaBox").Index(0)
+	if got.Kind() != Null {
+		t.Errorf("chained access over a malformed document = %+v, want the zero Value", got)
+	}
+}
+
+// TestValueUnresolvableReferenceIsNull checks that a Value marked as an
+// indirect reference but with no pdfReader to resolve it through
+// resolves to the zero Value instead of panicking.
+func TestValueUnresolvableReferenceIsNull(t *testing.T) {
+	ref := Value{isRef: true, ref: objReference{objNum: 99}}
+	if got := ref.Kind(); got != Null {
+		t.Errorf("unresolvable reference Kind() = %v, want Null", got)
+	}
+	if got := ref.Int64(); got != 0 {
+		t.Errorf("unresolvable reference Int64() = %d, want 0", got)
+	}
+}
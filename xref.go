@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2017 Jerry Caligiure (Gmail: caligiure.ja)
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Noofbiz/gofpdf/filter"
+)
+
+// isXRefStreamAt reports whether the xref section at offset is stored as
+// a PDF 1.5 cross-reference stream (`N G obj << /Type /XRef ... >>
+// stream`) rather than the classic `xref` keyword table.
+func (r *pdfReader) isXRefStreamAt(offset int64) (bool, error) {
+	lx := newPDFLexer(r.file, offset, r.pdfSize()-offset)
+	tok, err := lx.Next()
+	if err != nil {
+		return false, err
+	}
+	return tok.kind == tokInteger, nil
+}
+
+// parseXRefStreamAt parses the cross-reference stream object beginning at
+// offset, decodes its entries according to /W, /Index and /Size, and
+// returns them as a standalone xref. The stream's own dictionary is
+// returned as that xref's trailer, since an xref stream plays the role
+// the classic `trailer` dictionary plays for a plain xref table.
+func (r *pdfReader) parseXRefStreamAt(offset int64) (xref, error) {
+	var sec xref
+
+	lx := newPDFLexer(r.file, offset, r.pdfSize()-offset)
+
+	numTok, err := lx.Next()
+	if err != nil {
+		return xref{}, err
+	}
+	genTok, err := lx.Next()
+	if err != nil {
+		return xref{}, err
+	}
+	objTok, err := lx.Next()
+	if err != nil {
+		return xref{}, err
+	}
+	if numTok.kind != tokInteger || genTok.kind != tokInteger ||
+		objTok.kind != tokKeyword || objTok.val.(string) != "obj" {
+		return xref{}, fmt.Errorf("pdf: malformed xref stream object header at offset %d", offset)
+	}
+
+	saved := r.lexer
+	r.lexer = lx
+	dict, err := r.parsePDFDict()
+	if err != nil {
+		r.lexer = saved
+		return xref{}, err
+	}
+	streamTok, err := r.lexer.Next()
+	r.lexer = saved
+	if err != nil {
+		return xref{}, err
+	}
+	if streamTok.kind != tokStream {
+		return xref{}, fmt.Errorf("pdf: xref stream object at offset %d has no stream body", offset)
+	}
+	rawStart := streamTok.val.(int64)
+
+	length := dict.Key("Length").Int64()
+	if length <= 0 {
+		return xref{}, fmt.Errorf("pdf: xref stream at offset %d has no usable /Length", offset)
+	}
+	raw := make([]byte, length)
+	if _, err := r.file.ReadAt(raw, rawStart); err != nil && err != io.EOF {
+		return xref{}, err
+	}
+
+	decoded := raw
+	if filterName := dict.Key("Filter").Name(); filterName != "" {
+		f, ok := filter.Get(filterName)
+		if !ok {
+			return xref{}, fmt.Errorf("pdf: xref stream filter %q not supported", filterName)
+		}
+		rdr, err := f.Decode(bytes.NewReader(raw), parmsFromValue(dict.Key("DecodeParms")))
+		if err != nil {
+			return xref{}, fmt.Errorf("pdf: decoding xref stream: %w", err)
+		}
+		decoded, err = io.ReadAll(rdr)
+		if err != nil {
+			return xref{}, fmt.Errorf("pdf: decoding xref stream: %w", err)
+		}
+	}
+
+	w := dict.Key("W")
+	if w.Kind() != Array || w.Len() != 3 {
+		return xref{}, fmt.Errorf("pdf: xref stream at offset %d has malformed /W", offset)
+	}
+	w1, w2, w3 := int(w.Index(0).Int64()), int(w.Index(1).Int64()), int(w.Index(2).Int64())
+	recordSize := w1 + w2 + w3
+
+	var index []int64
+	if idx := dict.Key("Index"); idx.Kind() == Array {
+		for i := 0; i < idx.Len(); i++ {
+			index = append(index, idx.Index(i).Int64())
+		}
+	} else {
+		index = []int64{0, dict.Key("Size").Int64()}
+	}
+
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		first, count := index[i], index[i+1]
+		for n := int64(0); n < count; n++ {
+			if pos+recordSize > len(decoded) {
+				return xref{}, fmt.Errorf("pdf: xref stream at offset %d truncated", offset)
+			}
+			rec := decoded[pos : pos+recordSize]
+			pos += recordSize
+
+			fieldType := int64(1)
+			if w1 > 0 {
+				fieldType = beInt(rec[:w1])
+			}
+			f2 := beInt(rec[w1 : w1+w2])
+			f3 := beInt(rec[w1+w2 : w1+w2+w3])
+
+			objNum := int(first + n)
+			row := xrefObjData{objNum: objNum}
+			switch fieldType {
+			case 0:
+				row.inUse = false
+				row.offset = int(f2)
+				row.generationNumber = int(f3)
+			case 1:
+				row.inUse = true
+				row.offset = int(f2)
+				row.generationNumber = int(f3)
+			case 2:
+				row.inUse = true
+				row.compressed = true
+				row.objStmNum = int(f2)
+				row.objStmIndex = int(f3)
+			default:
+				return xref{}, fmt.Errorf("pdf: xref stream at offset %d has unknown entry type %d", offset, fieldType)
+			}
+			sec.objTable = append(sec.objTable, row)
+		}
+	}
+
+	sec.trailer = dict
+	return sec, nil
+}
+
+// beInt decodes b as a big-endian unsigned integer. A zero-length b
+// (a field width of 0, meaning the spec's default for that column)
+// decodes to 0.
+func beInt(b []byte) int64 {
+	var n int64
+	for _, c := range b {
+		n = n<<8 | int64(c)
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+package gofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildXRefStreamFixture writes a minimal PDF whose cross-reference
+// section is a PDF 1.5 stream (rather than a classic `xref` table):
+// object 0 is free and object 2 is an in-use direct object.
+func buildXRefStreamFixture(t *testing.T, path string) (obj2Offset, xrefOffset int64) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	obj2Offset = int64(buf.Len())
+	buf.WriteString("2 0 obj\n<< /Foo (bar) >>\nendobj\n")
+
+	xrefOffset = int64(buf.Len())
+	// W = [1, 2, 1]: 1-byte type, 2-byte offset, 1-byte generation field.
+	records := []byte{
+		0, 0, 0, 0, // object 0: free
+		1, byte(obj2Offset >> 8), byte(obj2Offset), 0, // object 2: in use, direct
+	}
+	fmt.Fprintf(&buf, "3 0 obj\n<< /Type /XRef /Size 3 /W [1 2 1] /Index [0 1 2 1] /Length %d >>\nstream\n", len(records))
+	buf.Write(records)
+	buf.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	return obj2Offset, xrefOffset
+}
+
+// TestParseXRefStreamAt checks that a PDF 1.5 cross-reference stream
+// decodes both a free (type 0) and an in-use direct (type 1) entry.
+func TestParseXRefStreamAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xrefstream.pdf")
+	obj2Offset, xrefOffset := buildXRefStreamFixture(t, path)
+
+	var r pdfReader
+	if err := r.openFile(path); err != nil {
+		t.Fatalf("openFile: %v", err)
+	}
+	defer r.closeFile()
+
+	ok, err := r.isXRefStreamAt(xrefOffset)
+	if err != nil {
+		t.Fatalf("isXRefStreamAt: %v", err)
+	}
+	if !ok {
+		t.Fatalf("isXRefStreamAt = false, want true")
+	}
+
+	sec, err := r.parseXRefStreamAt(xrefOffset)
+	if err != nil {
+		t.Fatalf("parseXRefStreamAt: %v", err)
+	}
+	if len(sec.objTable) != 2 {
+		t.Fatalf("len(objTable) = %d, want 2", len(sec.objTable))
+	}
+
+	row0, row2 := sec.objTable[0], sec.objTable[1]
+	if row0.objNum != 0 || row0.inUse {
+		t.Errorf("row0 = %+v, want free object 0", row0)
+	}
+	if row2.objNum != 2 || !row2.inUse || row2.compressed || int64(row2.offset) != obj2Offset {
+		t.Errorf("row2 = %+v, want in-use direct object at offset %d", row2, obj2Offset)
+	}
+	if sec.trailer.Key("Size").Int64() != 3 {
+		t.Errorf("trailer /Size = %d, want 3", sec.trailer.Key("Size").Int64())
+	}
+}